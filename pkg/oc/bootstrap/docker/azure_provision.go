@@ -0,0 +1,15 @@
+package docker
+
+import (
+	"fmt"
+)
+
+// newAzureProvisioner always fails: standing up a multi-VM OpenShift
+// cluster on Azure via ARM templates requires an ARM client to submit and
+// poll deployments, which this package doesn't have. Rather than ship
+// EnsureRuntime/StartMaster/StartNode/Teardown scaffolding that validates
+// credentials and prints progress before failing on the first real ARM
+// call, --provider=azure is rejected immediately, before any of that runs.
+func newAzureProvisioner(c *CommonStartConfig) (ClusterProvisioner, error) {
+	return nil, fmt.Errorf("--provider=%s is not yet implemented", ProviderAzure)
+}
@@ -0,0 +1,143 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const checkpointFileName = "checkpoint.json"
+
+// taskCheckpoint records the last known state of one c.Tasks entry: whether
+// it completed, a hash of the inputs it ran with, and a tail of the output
+// it produced, so --resume can tell whether the task is still valid without
+// re-running it.
+type taskCheckpoint struct {
+	Completed   bool   `json:"completed"`
+	InputHash   string `json:"inputHash"`
+	CompletedAt string `json:"completedAt,omitempty"`
+	Log         string `json:"log,omitempty"`
+}
+
+// startCheckpoint is the on-disk record of every task's last run, persisted
+// under LocalConfigDir so a later 'cluster up --resume' can pick up where a
+// flaky or interrupted run left off.
+type startCheckpoint struct {
+	Tasks map[string]taskCheckpoint `json:"tasks"`
+}
+
+func (c *CommonStartConfig) checkpointPath() string {
+	return filepath.Join(c.LocalConfigDir, checkpointFileName)
+}
+
+// loadCheckpoint reads the checkpoint file, returning an empty checkpoint if
+// none exists yet.
+func (c *CommonStartConfig) loadCheckpoint() (*startCheckpoint, error) {
+	data, err := ioutil.ReadFile(c.checkpointPath())
+	if os.IsNotExist(err) {
+		return &startCheckpoint{Tasks: map[string]taskCheckpoint{}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cp := &startCheckpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+	if cp.Tasks == nil {
+		cp.Tasks = map[string]taskCheckpoint{}
+	}
+	return cp, nil
+}
+
+func (c *CommonStartConfig) saveCheckpoint(cp *startCheckpoint) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.checkpointPath(), data, 0644)
+}
+
+// taskInputHash hashes the start configuration fields that can change a
+// task's outcome (image, version, ports, IPs, proxy settings) together with
+// the task's own name, so each task's checkpoint entry carries its own hash.
+// Every task hashes the same configuration fields, so a change to any of
+// them invalidates every task's entry.
+func (c *CommonStartConfig) taskInputHash(taskName string) string {
+	inputs := struct {
+		Task           string
+		Image          string
+		ImageVersion   string
+		ImageStreams   string
+		ServerIP       string
+		AdditionalIPs  []string
+		UsePorts       []int
+		RoutingSuffix  string
+		PublicHostname string
+		HTTPProxy      string
+		HTTPSProxy     string
+		NoProxy        []string
+		Provider       string
+	}{
+		Task:           taskName,
+		Image:          c.Image,
+		ImageVersion:   c.ImageVersion,
+		ImageStreams:   c.ImageStreams,
+		ServerIP:       c.ServerIP,
+		AdditionalIPs:  c.AdditionalIPs,
+		UsePorts:       c.UsePorts,
+		RoutingSuffix:  c.RoutingSuffix,
+		PublicHostname: c.PublicHostname,
+		HTTPProxy:      c.HTTPProxy,
+		HTTPSProxy:     c.HTTPSProxy,
+		NoProxy:        c.NoProxy,
+		Provider:       c.Provider,
+	}
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		// json.Marshal only fails here for unsupported types, which this
+		// struct never contains; fall back to the task name so a failure to
+		// hash never blocks the task from running.
+		return taskName
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// resumableTask reports whether taskName can be skipped: --resume is set, it
+// is not the task named by --force-task, and its checkpoint entry completed
+// successfully with the same input hash it would run with now.
+func (c *CommonStartConfig) resumableTask(cp *startCheckpoint, taskName string) (taskCheckpoint, bool) {
+	if !c.Resume || taskName == c.ForceTask {
+		return taskCheckpoint{}, false
+	}
+	entry, ok := cp.Tasks[taskName]
+	if !ok || !entry.Completed {
+		return taskCheckpoint{}, false
+	}
+	if entry.InputHash != c.taskInputHash(taskName) {
+		return taskCheckpoint{}, false
+	}
+	return entry, true
+}
+
+const checkpointLogTailBytes = 4096
+
+// recordTaskResult updates and persists the checkpoint entry for taskName
+// after it ran (or failed to run); the caller's own error handling decides
+// whether to continue or abort the start loop.
+func (c *CommonStartConfig) recordTaskResult(cp *startCheckpoint, taskName string, completed bool, log string) error {
+	if len(log) > checkpointLogTailBytes {
+		log = log[len(log)-checkpointLogTailBytes:]
+	}
+	entry := taskCheckpoint{Completed: completed, InputHash: c.taskInputHash(taskName), Log: log}
+	if completed {
+		entry.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	cp.Tasks[taskName] = entry
+	return c.saveCheckpoint(cp)
+}
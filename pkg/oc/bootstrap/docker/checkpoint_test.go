@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"testing"
+)
+
+func TestTaskInputHashStableAndSensitiveToInputs(t *testing.T) {
+	base := &CommonStartConfig{Image: "openshift/origin", ImageVersion: "v3.9", ServerIP: "127.0.0.1"}
+
+	if h1, h2 := base.taskInputHash("InstallRegistry"), base.taskInputHash("InstallRegistry"); h1 != h2 {
+		t.Errorf("taskInputHash is not stable across calls with unchanged inputs: %q != %q", h1, h2)
+	}
+
+	if h1, h2 := base.taskInputHash("InstallRegistry"), base.taskInputHash("InstallRouter"); h1 == h2 {
+		t.Errorf("taskInputHash(%q) == taskInputHash(%q) = %q, want different hashes for different task names", "InstallRegistry", "InstallRouter", h1)
+	}
+
+	changed := &CommonStartConfig{Image: "openshift/origin", ImageVersion: "v3.10", ServerIP: "127.0.0.1"}
+	if h1, h2 := base.taskInputHash("InstallRegistry"), changed.taskInputHash("InstallRegistry"); h1 == h2 {
+		t.Errorf("taskInputHash did not change when ImageVersion changed: both %q", h1)
+	}
+}
+
+func TestResumableTask(t *testing.T) {
+	config := &CommonStartConfig{Image: "openshift/origin", ImageVersion: "v3.9"}
+	hash := config.taskInputHash("InstallRegistry")
+
+	tests := []struct {
+		name     string
+		resume   bool
+		force    string
+		cp       *startCheckpoint
+		wantOK   bool
+		wantSame bool // whether the returned entry should be the stored one
+	}{
+		{
+			name:   "resume not set",
+			resume: false,
+			cp:     &startCheckpoint{Tasks: map[string]taskCheckpoint{"InstallRegistry": {Completed: true, InputHash: hash}}},
+			wantOK: false,
+		},
+		{
+			name:   "forced task is never resumed",
+			resume: true,
+			force:  "InstallRegistry",
+			cp:     &startCheckpoint{Tasks: map[string]taskCheckpoint{"InstallRegistry": {Completed: true, InputHash: hash}}},
+			wantOK: false,
+		},
+		{
+			name:   "no checkpoint entry",
+			resume: true,
+			cp:     &startCheckpoint{Tasks: map[string]taskCheckpoint{}},
+			wantOK: false,
+		},
+		{
+			name:   "checkpoint entry not completed",
+			resume: true,
+			cp:     &startCheckpoint{Tasks: map[string]taskCheckpoint{"InstallRegistry": {Completed: false, InputHash: hash}}},
+			wantOK: false,
+		},
+		{
+			name:   "input hash mismatch",
+			resume: true,
+			cp:     &startCheckpoint{Tasks: map[string]taskCheckpoint{"InstallRegistry": {Completed: true, InputHash: "stale"}}},
+			wantOK: false,
+		},
+		{
+			name:     "resumable",
+			resume:   true,
+			cp:       &startCheckpoint{Tasks: map[string]taskCheckpoint{"InstallRegistry": {Completed: true, InputHash: hash}}},
+			wantOK:   true,
+			wantSame: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Resume = tt.resume
+			config.ForceTask = tt.force
+			entry, ok := config.resumableTask(tt.cp, "InstallRegistry")
+			if ok != tt.wantOK {
+				t.Fatalf("resumableTask() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.wantSame && entry.InputHash != hash {
+				t.Errorf("resumableTask() returned entry with InputHash %q, want %q", entry.InputHash, hash)
+			}
+		})
+	}
+}
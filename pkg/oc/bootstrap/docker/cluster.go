@@ -0,0 +1,28 @@
+package docker
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	osclientcmd "github.com/openshift/origin/pkg/oc/cli/util/clientcmd"
+)
+
+const (
+	// CmdClusterRecommendedName is the recommended command name for the
+	// parent "cluster" command that groups NewCmdUp and NewCmdVolume.
+	CmdClusterRecommendedName = "cluster"
+)
+
+// NewCmdCluster creates the "cluster" command, grouping the "up" and
+// "volume" subcommands so both are reachable as "oc cluster up" and
+// "oc cluster volume".
+func NewCmdCluster(name, fullName string, f *osclientcmd.Factory, out, errout io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   name,
+		Short: "Start a local OpenShift cluster and manage its volumes",
+	}
+	cmd.AddCommand(NewCmdUp(CmdUpRecommendedName, fullName+" "+CmdUpRecommendedName, f, out, errout))
+	cmd.AddCommand(NewCmdVolume(CmdVolumeRecommendedName, fullName+" "+CmdVolumeRecommendedName, f, out, errout))
+	return cmd
+}
@@ -0,0 +1,85 @@
+package docker
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/localcmd"
+)
+
+// e2eSuite describes the focus/skip regexes used to select the Ginkgo specs
+// that make up one of the curated --e2e suites.
+type e2eSuite struct {
+	focus string
+	skip  string
+}
+
+var e2eSuites = map[string]e2eSuite{
+	"smoke":       {focus: `\[Smoke\]`},
+	"conformance": {focus: `\[Conformance\]`},
+	"networking":  {focus: `\[networking\]`},
+	"openshift":   {focus: `\[Feature:.*\]`, skip: `\[Disruptive\]|\[Skipped\]`},
+}
+
+// RunE2E discovers the kubeconfig written by Start, sets up the standard
+// e2e environment, and invokes the test/extended Ginkgo binary with a
+// focus/skip regex selected by --e2e, streaming JUnit XML and logs to
+// --e2e-artifacts-dir. It returns a non-nil error if the suite fails.
+func (c *ClientStartConfig) RunE2E(out io.Writer) error {
+	suite, ok := e2eSuites[c.E2ESuite]
+	if !ok {
+		return fmt.Errorf("unrecognized --e2e suite %q, must be one of: smoke, conformance, networking, openshift", c.E2ESuite)
+	}
+	focus := suite.focus
+	if len(c.E2EFocus) > 0 {
+		focus = c.E2EFocus
+	}
+	skip := suite.skip
+	if len(c.E2ESkip) > 0 {
+		skip = c.E2ESkip
+	}
+
+	artifactsDir := c.E2EArtifactsDir
+	if len(artifactsDir) == 0 {
+		artifactsDir = filepath.Join(c.LocalConfigDir, "e2e")
+	}
+	if err := os.MkdirAll(artifactsDir, 0755); err != nil {
+		return fmt.Errorf("cannot create e2e artifacts directory %q: %v", artifactsDir, err)
+	}
+
+	kubeConfig := filepath.Join(c.LocalConfigDir, "master", "admin.kubeconfig")
+	if _, err := os.Stat(kubeConfig); err != nil {
+		return fmt.Errorf("cannot find kubeconfig written by 'cluster up' at %q: %v", kubeConfig, err)
+	}
+
+	fmt.Fprintf(out, "Running %q e2e suite against %s (focus=%q, skip=%q)\n", c.E2ESuite, kubeConfig, focus, skip)
+
+	// Set the same environment the OpenShift e2e CI job uses so the
+	// extended.test binary discovers the cluster we just started.
+	os.Setenv("KUBECONFIG", kubeConfig)
+	os.Setenv("TEST_FOCUS", focus)
+	os.Setenv("TIMEOUT", e2eTimeoutOrDefault(c.E2ETimeout).String())
+	os.Setenv("ARTIFACTS", artifactsDir)
+
+	err := localcmd.New("extended.test").Args(
+		fmt.Sprintf("--ginkgo.focus=%s", focus),
+		fmt.Sprintf("--ginkgo.skip=%s", skip),
+		fmt.Sprintf("--junit-report=%s", filepath.Join(artifactsDir, "junit.xml")),
+	).Run()
+	if err != nil {
+		return fmt.Errorf("e2e suite %q failed, see %s for logs and JUnit output: %v", c.E2ESuite, artifactsDir, err)
+	}
+	fmt.Fprintf(out, "e2e suite %q passed; results written to %s\n", c.E2ESuite, artifactsDir)
+	return nil
+}
+
+// e2eTimeoutOrDefault returns --e2e-timeout, defaulting to 30 minutes when unset.
+func e2eTimeoutOrDefault(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 30 * time.Minute
+	}
+	return d
+}
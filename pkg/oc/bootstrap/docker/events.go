@@ -0,0 +1,270 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const outputJSON = "json"
+
+// Event is published once for every state change of a start task. The
+// human-readable printer, the --output=json stream, and the optional
+// --progress-http-addr server are all subscribers driven off the same
+// stream, so none of them can drift out of sync with what actually happened.
+type Event interface {
+	eventType() string
+}
+
+// TaskStarted is published when a start task begins running.
+type TaskStarted struct {
+	Task string `json:"task"`
+}
+
+func (TaskStarted) eventType() string { return "TaskStarted" }
+
+// TaskProgress is published for incremental, human-readable progress within
+// a still-running task. No task in this package currently emits it; it
+// exists so a task can report progress beyond the detailed-output log.
+type TaskProgress struct {
+	Task    string `json:"task"`
+	Message string `json:"message"`
+}
+
+func (TaskProgress) eventType() string { return "TaskProgress" }
+
+// TaskSucceeded is published when a start task completes without error.
+type TaskSucceeded struct {
+	Task     string        `json:"task"`
+	Duration time.Duration `json:"duration"`
+}
+
+func (TaskSucceeded) eventType() string { return "TaskSucceeded" }
+
+// TaskFailed is published when a start task returns an error, ending the
+// start loop. err holds the original error so the printer subscriber can
+// render it exactly as before (including any WithSolution/WithDetails
+// formatting); Err and Cause are its string form for subscribers that only
+// deal in plain data.
+type TaskFailed struct {
+	Task  string `json:"task"`
+	Err   string `json:"err"`
+	Cause string `json:"cause,omitempty"`
+
+	err error
+}
+
+func (TaskFailed) eventType() string { return "TaskFailed" }
+
+// causer is implemented by errors that wrap another error, matching the
+// convention used by this package's errors.Error type.
+type causer interface {
+	Cause() error
+}
+
+func newTaskFailed(task string, err error) TaskFailed {
+	ev := TaskFailed{Task: task, Err: err.Error(), err: err}
+	if c, ok := err.(causer); ok {
+		if cause := c.Cause(); cause != nil {
+			ev.Cause = cause.Error()
+		}
+	}
+	return ev
+}
+
+// ClusterReady is published once, after every start task has succeeded. It
+// carries the same fields ServerInfo used to format directly into text; see
+// formatClusterReady for the human-readable rendering.
+type ClusterReady struct {
+	MasterURL            string   `json:"masterURL"`
+	MetricsURL           string   `json:"metricsURL,omitempty"`
+	LoggingURL           string   `json:"loggingURL,omitempty"`
+	LoggedInUser         string   `json:"loggedInUser,omitempty"`
+	AdditionalContainers []string `json:"additionalContainers,omitempty"`
+	ProxyWarnings        []string `json:"proxyWarnings,omitempty"`
+}
+
+func (ClusterReady) eventType() string { return "ClusterReady" }
+
+// Subscriber receives every event published on an eventBus.
+type Subscriber interface {
+	HandleEvent(Event)
+}
+
+// eventBus fans the single stream of start events out to every subscriber in
+// registration order.
+type eventBus struct {
+	subscribers []Subscriber
+}
+
+func newEventBus(subscribers ...Subscriber) *eventBus {
+	return &eventBus{subscribers: subscribers}
+}
+
+func (b *eventBus) Publish(e Event) {
+	for _, s := range b.subscribers {
+		s.HandleEvent(e)
+	}
+}
+
+// newEventBus builds the event bus used by a start loop: the human-readable
+// printer subscriber always runs, with a JSON-lines subscriber added for
+// --output=json and an HTTP subscriber added for --progress-http-addr. The
+// returned io.Closer, if non-nil, must be closed once the start loop exits.
+func (c *CommonStartConfig) newEventBus(out io.Writer, printer *TaskPrinter) (*eventBus, io.Closer, error) {
+	subscribers := []Subscriber{&printerSubscriber{printer: printer, out: out}}
+
+	if c.Output == outputJSON {
+		subscribers = append(subscribers, newJSONLinesSubscriber(out))
+	}
+
+	var closer io.Closer
+	if len(c.ProgressHTTPAddr) > 0 {
+		httpSub, err := newHTTPSubscriber(c.ProgressHTTPAddr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot start progress HTTP server on %q: %v", c.ProgressHTTPAddr, err)
+		}
+		fmt.Fprintf(out, "Publishing start progress at http://%s/events\n", httpSub.Addr())
+		subscribers = append(subscribers, httpSub)
+		closer = httpSub
+	}
+
+	return newEventBus(subscribers...), closer, nil
+}
+
+// printerSubscriber renders events through the existing human-readable
+// TaskPrinter, preserving the output 'cluster up' has always produced.
+type printerSubscriber struct {
+	printer *TaskPrinter
+	out     io.Writer
+}
+
+func (s *printerSubscriber) HandleEvent(e Event) {
+	switch ev := e.(type) {
+	case TaskStarted:
+		s.printer.StartTask(ev.Task)
+	case TaskSucceeded:
+		s.printer.Success()
+	case TaskFailed:
+		s.printer.Failure(ev.err)
+	case ClusterReady:
+		fmt.Fprint(s.out, formatClusterReady(ev))
+	}
+}
+
+// formatClusterReady renders the same message ServerInfo used to print
+// directly, from the fields now carried on the ClusterReady event.
+func formatClusterReady(ev ClusterReady) string {
+	metricsInfo := ""
+	if len(ev.MetricsURL) > 0 {
+		metricsInfo = fmt.Sprintf("The metrics service is available at:\n    %s\n\n", ev.MetricsURL)
+	}
+	loggingInfo := ""
+	if len(ev.LoggingURL) > 0 {
+		loggingInfo = fmt.Sprintf("The kibana logging UI is available at:\n    %s\n\n", ev.LoggingURL)
+	}
+	msg := fmt.Sprintf("OpenShift server started.\n\n"+
+		"The server is accessible via web console at:\n"+
+		"    %s\n\n%s%s", ev.MasterURL, metricsInfo, loggingInfo)
+
+	if len(ev.LoggedInUser) > 0 {
+		msg += fmt.Sprintf("You are logged in as:\n"+
+			"    User:     %s\n"+
+			"    Password: <any value>\n\n", ev.LoggedInUser)
+		msg += "To login as administrator:\n" +
+			"    oc login -u system:admin\n\n"
+	}
+
+	if len(ev.AdditionalContainers) > 0 {
+		msg += fmt.Sprintf("\nAdditional containers started: %s\n", strings.Join(ev.AdditionalContainers, ", "))
+	}
+
+	for _, w := range ev.ProxyWarnings {
+		msg += fmt.Sprintf("WARNING: %s\n", w)
+	}
+
+	return msg
+}
+
+// jsonLinesSubscriber streams one JSON object per event to out, selected by
+// --output=json, for scripting and CI consumption.
+type jsonLinesSubscriber struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+func newJSONLinesSubscriber(out io.Writer) *jsonLinesSubscriber {
+	return &jsonLinesSubscriber{out: out}
+}
+
+func (s *jsonLinesSubscriber) HandleEvent(e Event) {
+	line, err := marshalEvent(e)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintln(s.out, string(line))
+}
+
+type eventEnvelope struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+func marshalEvent(e Event) ([]byte, error) {
+	return json.Marshal(eventEnvelope{Type: e.eventType(), Data: e})
+}
+
+// httpSubscriber serves the event stream published so far as a JSON array on
+// a local port, so a wrapper UI or supervising job can watch 'cluster up' in
+// real time by polling GET /events.
+type httpSubscriber struct {
+	mu       sync.Mutex
+	events   []eventEnvelope
+	listener net.Listener
+	server   *http.Server
+}
+
+func newHTTPSubscriber(addr string) (*httpSubscriber, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &httpSubscriber{listener: ln}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", s.handleEvents)
+	s.server = &http.Server{Handler: mux}
+	go s.server.Serve(ln)
+	return s, nil
+}
+
+// Addr returns the address the HTTP subscriber is actually listening on,
+// resolving any ephemeral port requested via --progress-http-addr.
+func (s *httpSubscriber) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *httpSubscriber) HandleEvent(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, eventEnvelope{Type: e.eventType(), Data: e})
+}
+
+func (s *httpSubscriber) handleEvents(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	events := append([]eventEnvelope{}, s.events...)
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// Close stops the progress HTTP server.
+func (s *httpSubscriber) Close() error {
+	return s.server.Close()
+}
@@ -0,0 +1,60 @@
+package docker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NodePoolSpec describes one named pool of additional node containers to
+// join to the cluster, e.g. "infra=2,role=infra,zone=a" from a repeatable
+// --node-pool flag.
+type NodePoolSpec struct {
+	Name   string
+	Count  int
+	Labels map[string]string
+}
+
+// parseNodePools parses the raw --node-pool flag values ("name=count,k=v,...")
+// into NodePoolSpecs. Each entry must start with "name=count"; any further
+// comma-separated "key=value" pairs become node labels.
+func parseNodePools(raw []string) ([]NodePoolSpec, error) {
+	pools := make([]NodePoolSpec, 0, len(raw))
+	for _, entry := range raw {
+		fields := strings.Split(entry, ",")
+		if len(fields) == 0 || !strings.Contains(fields[0], "=") {
+			return nil, fmt.Errorf("invalid --node-pool %q, expected name=count[,label=value,...]", entry)
+		}
+		nameCount := strings.SplitN(fields[0], "=", 2)
+		count, err := strconv.Atoi(nameCount[1])
+		if err != nil || count < 1 {
+			return nil, fmt.Errorf("invalid --node-pool %q, count must be a positive integer", entry)
+		}
+		spec := NodePoolSpec{Name: nameCount[0], Count: count, Labels: map[string]string{}}
+		for _, label := range fields[1:] {
+			kv := strings.SplitN(label, "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid label %q in --node-pool %q, expected key=value", label, entry)
+			}
+			spec.Labels[kv[0]] = kv[1]
+		}
+		pools = append(pools, spec)
+	}
+	return pools, nil
+}
+
+// parseNodeLabels parses the "key=value,key=value" form used by --node-labels.
+func parseNodeLabels(raw string) (map[string]string, error) {
+	labels := map[string]string{}
+	if len(raw) == 0 {
+		return labels, nil
+	}
+	for _, pair := range strings.Split(raw, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid --node-labels entry %q, expected key=value", pair)
+		}
+		labels[kv[0]] = kv[1]
+	}
+	return labels, nil
+}
@@ -0,0 +1,135 @@
+package docker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNodePools(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     []string
+		want    []NodePoolSpec
+		wantErr bool
+	}{
+		{
+			name: "name and count only",
+			raw:  []string{"infra=2"},
+			want: []NodePoolSpec{{Name: "infra", Count: 2, Labels: map[string]string{}}},
+		},
+		{
+			name: "name, count, and labels",
+			raw:  []string{"infra=2,role=infra,zone=a"},
+			want: []NodePoolSpec{{Name: "infra", Count: 2, Labels: map[string]string{"role": "infra", "zone": "a"}}},
+		},
+		{
+			name: "multiple entries",
+			raw:  []string{"infra=2", "compute=3,role=compute"},
+			want: []NodePoolSpec{
+				{Name: "infra", Count: 2, Labels: map[string]string{}},
+				{Name: "compute", Count: 3, Labels: map[string]string{"role": "compute"}},
+			},
+		},
+		{
+			name:    "missing equals",
+			raw:     []string{"infra"},
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric count",
+			raw:     []string{"infra=two"},
+			wantErr: true,
+		},
+		{
+			name:    "zero count",
+			raw:     []string{"infra=0"},
+			wantErr: true,
+		},
+		{
+			name:    "negative count",
+			raw:     []string{"infra=-1"},
+			wantErr: true,
+		},
+		{
+			name:    "malformed label",
+			raw:     []string{"infra=2,role"},
+			wantErr: true,
+		},
+		{
+			name: "empty input",
+			raw:  []string{},
+			want: []NodePoolSpec{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNodePools(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseNodePools(%v) = %v, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseNodePools(%v) returned unexpected error: %v", tt.raw, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNodePools(%v) = %#v, want %#v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNodeLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name: "empty string",
+			raw:  "",
+			want: map[string]string{},
+		},
+		{
+			name: "single pair",
+			raw:  "role=infra",
+			want: map[string]string{"role": "infra"},
+		},
+		{
+			name: "multiple pairs",
+			raw:  "role=infra,zone=a",
+			want: map[string]string{"role": "infra", "zone": "a"},
+		},
+		{
+			name:    "missing value",
+			raw:     "role",
+			wantErr: true,
+		},
+		{
+			name:    "missing value among valid pairs",
+			raw:     "role=infra,zone",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNodeLabels(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseNodeLabels(%q) = %v, want error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseNodeLabels(%q) returned unexpected error: %v", tt.raw, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseNodeLabels(%q) = %#v, want %#v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package docker
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/openshift"
+)
+
+const (
+	// ProviderDocker runs OpenShift as a single container on a local Docker daemon.
+	ProviderDocker = "docker"
+	// ProviderAzure names the Azure provider; --provider=azure is accepted as
+	// a value but always rejected with an error (see newAzureProvisioner), and
+	// no Azure resources are created.
+	ProviderAzure = "azure"
+	// ProviderSSH runs the OpenShift container on a remote host reachable over SSH.
+	ProviderSSH = "ssh"
+)
+
+// ClusterProvisioner abstracts the steps needed to bring up and tear down an
+// OpenShift cluster so that CommonStartConfig is not hard-coded to the local
+// Docker flow. Additional backends can be added by implementing this
+// interface and wiring a name into provisionerFor.
+type ClusterProvisioner interface {
+	// EnsureRuntime verifies that the provisioner's backend is reachable and
+	// ready to accept a cluster, e.g. a local Docker daemon is running, or
+	// cloud credentials and quota are valid. It replaces the Docker-specific
+	// health/version checks for backends that don't use Docker directly.
+	EnsureRuntime(out io.Writer) error
+
+	// StartMaster brings up the OpenShift master(s) for the cluster.
+	StartMaster(out io.Writer) error
+
+	// StartNode brings up an additional OpenShift node and joins it to the
+	// master(s) started by StartMaster.
+	StartNode(out io.Writer) error
+
+	// Teardown tears down everything this provisioner created.
+	Teardown(out io.Writer) error
+}
+
+// isFullyCustomProvider reports whether provider replaces the entire cluster
+// startup flow (master start included) rather than just supplying a Docker
+// daemon for the normal container-based flow to run against.
+func isFullyCustomProvider(provider string) bool {
+	return provider == ProviderAzure
+}
+
+// provisionerFor returns the ClusterProvisioner implementation selected by
+// the --provider flag.
+func (c *CommonStartConfig) provisionerFor() (ClusterProvisioner, error) {
+	switch c.Provider {
+	case "", ProviderDocker:
+		return &dockerProvisioner{config: c}, nil
+	case ProviderAzure:
+		return newAzureProvisioner(c)
+	case ProviderSSH:
+		return newSSHProvisioner(c)
+	default:
+		return nil, fmt.Errorf("unrecognized --provider %q, must be one of: %s, %s, %s",
+			c.Provider, ProviderDocker, ProviderAzure, ProviderSSH)
+	}
+}
+
+// dockerProvisioner is the default ClusterProvisioner, backed by a local
+// Docker daemon. It delegates to the checks and tasks CommonStartConfig
+// already performs for the Docker path.
+type dockerProvisioner struct {
+	config *CommonStartConfig
+}
+
+func (p *dockerProvisioner) EnsureRuntime(out io.Writer) error {
+	if err := p.config.CheckDockerVersion(out); err != nil {
+		return err
+	}
+	if !p.config.SkipRegistryCheck {
+		if err := p.config.CheckDockerInsecureRegistry(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *dockerProvisioner) StartMaster(out io.Writer) error {
+	// The Docker path starts the master as part of ClientStartConfig.Start,
+	// via StartSelfHosted; nothing additional is required here.
+	return nil
+}
+
+// StartNode is not implemented yet: starting an additional node container
+// requires joining it to the SDN of the master StartMaster already brought
+// up, which this provisioner cannot do today. Rather than silently
+// reporting success for --node-count/--node-pool and leaving nodes that
+// were never actually started, it fails loudly so Start() stops before
+// PostClusterStartupMutations runs.
+func (p *dockerProvisioner) StartNode(out io.Writer) error {
+	names := p.config.additionalNodeNames()
+	if len(names) == 0 {
+		return nil
+	}
+	return fmt.Errorf("starting additional node containers (%s) is not yet implemented; use --node-count=0 and no --node-pool", strings.Join(names, ", "))
+}
+
+func (p *dockerProvisioner) Teardown(out io.Writer) error {
+	return p.config.DockerHelper().RemoveContainer(openshift.OpenShiftContainer)
+}
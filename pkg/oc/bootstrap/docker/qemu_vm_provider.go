@@ -0,0 +1,182 @@
+package docker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/dockerhelper"
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/errors"
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/localcmd"
+)
+
+// qemuVMProvider backs --vm-driver=qemu: it boots a minimal cloud image
+// under a qemu-system-* binary, seeding it with cloud-init (an SSH key),
+// rather than requiring docker-machine, modeled on how podman-machine runs
+// a disposable Linux VM without a separate hypervisor framework. The VM's
+// only NIC is QEMU usermode/slirp networking, which is NAT'd: the host has
+// no route to whatever address the guest ends up with, only to the ports
+// slirp forwards (qemuSSHForwardPort, qemuDockerPort) on 127.0.0.1. IP()
+// returns that instead of a guest-side address.
+type qemuVMProvider struct {
+	config *CommonStartConfig
+}
+
+func newQEMUProvider(c *CommonStartConfig) *qemuVMProvider {
+	return &qemuVMProvider{config: c}
+}
+
+const (
+	qemuSSHForwardPort = "2223"
+	qemuDockerPort     = "2376"
+	qemuDiskSize       = "20G"
+)
+
+func (p *qemuVMProvider) vmDir(name string) string {
+	return filepath.Join(p.config.LocalConfigDir, "vms", name)
+}
+
+func (p *qemuVMProvider) pidFile(name string) string {
+	return filepath.Join(p.vmDir(name), "qemu.pid")
+}
+
+// Start creates the named VM's disk if it doesn't exist yet, rebuilds its
+// cloud-init seed on every call, and launches qemu-system-x86_64 as a
+// daemonized background process.
+func (p *qemuVMProvider) Start(name string) error {
+	if p.IsRunning(name) {
+		return nil
+	}
+	dir := p.vmDir(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.NewError("cannot create VM state directory %q", dir).WithCause(err)
+	}
+
+	seedISO := filepath.Join(dir, "seed.iso")
+	if err := p.writeCloudInitSeed(name, dir, seedISO); err != nil {
+		return err
+	}
+
+	diskImage := filepath.Join(dir, "disk.qcow2")
+	if _, err := os.Stat(diskImage); os.IsNotExist(err) {
+		if err := localcmd.New("qemu-img").Args("create", "-f", "qcow2", diskImage, qemuDiskSize).Run(); err != nil {
+			return errors.NewError("cannot create VM disk image %q", diskImage).WithCause(err)
+		}
+	}
+
+	if err := localcmd.New("qemu-system-x86_64").Args(
+		"-name", name,
+		"-m", "4096",
+		"-smp", "2",
+		"-daemonize",
+		"-pidfile", p.pidFile(name),
+		"-drive", "file="+diskImage+",if=virtio",
+		"-drive", "file="+seedISO+",if=virtio,format=raw",
+		"-netdev", fmt.Sprintf("user,id=net0,hostfwd=tcp::%s-:22,hostfwd=tcp::%s-:2376", qemuSSHForwardPort, qemuDockerPort),
+		"-device", "virtio-net-pci,netdev=net0",
+		"-display", "none",
+	).Run(); err != nil {
+		return errors.NewError("cannot start VM %q", name).WithCause(err)
+	}
+	return nil
+}
+
+// writeCloudInitSeed generates the SSH key, meta-data, and user-data
+// cloud-init expects, then builds them into the NoCloud seed ISO
+// qemu-system-x86_64 reads on first boot. It deliberately omits a
+// network-config: the guest's only NIC sits on QEMU's usermode/slirp
+// network, which already hands out an address and gateway over DHCP, so
+// cloud-init's default DHCP-on-all-interfaces behavior is what the guest
+// needs. Overriding it to a static address outside the slirp subnet would
+// leave the guest unable to reach its own gateway.
+func (p *qemuVMProvider) writeCloudInitSeed(name, dir, seedISO string) error {
+	publicKey, err := p.ensureSSHKey(dir)
+	if err != nil {
+		return err
+	}
+
+	metaData := fmt.Sprintf("instance-id: %s\nlocal-hostname: %s\n", name, name)
+	userData := "#cloud-config\n" +
+		"users:\n" +
+		"  - name: docker\n" +
+		"    ssh_authorized_keys:\n" +
+		fmt.Sprintf("      - %s\n", publicKey) +
+		"    sudo: ALL=(ALL) NOPASSWD:ALL\n" +
+		"runcmd:\n" +
+		"  - [ systemctl, enable, --now, docker ]\n"
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "meta-data"), []byte(metaData), 0644); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "user-data"), []byte(userData), 0644); err != nil {
+		return err
+	}
+
+	if err := localcmd.New("cloud-localds").Args(
+		seedISO,
+		filepath.Join(dir, "user-data"),
+		filepath.Join(dir, "meta-data"),
+	).Run(); err != nil {
+		return errors.NewError("cannot build cloud-init seed image %q", seedISO).WithCause(err)
+	}
+	return nil
+}
+
+func (p *qemuVMProvider) ensureSSHKey(dir string) (string, error) {
+	keyPath := filepath.Join(dir, "id_rsa")
+	if _, err := os.Stat(keyPath); os.IsNotExist(err) {
+		if err := localcmd.New("ssh-keygen").Args("-t", "rsa", "-N", "", "-f", keyPath, "-q").Run(); err != nil {
+			return "", errors.NewError("cannot generate SSH key for VM").WithCause(err)
+		}
+	}
+	publicKey, err := ioutil.ReadFile(keyPath + ".pub")
+	if err != nil {
+		return "", errors.NewError("cannot read generated SSH public key").WithCause(err)
+	}
+	return strings.TrimSpace(string(publicKey)), nil
+}
+
+// IsRunning reports whether the pidfile qemu-system-x86_64 wrote at Start
+// still names a live process.
+func (p *qemuVMProvider) IsRunning(name string) bool {
+	pid, err := ioutil.ReadFile(p.pidFile(name))
+	if err != nil {
+		return false
+	}
+	return localcmd.New("kill").Args("-0", strings.TrimSpace(string(pid))).Run() == nil
+}
+
+// IP returns 127.0.0.1: the guest sits behind QEMU usermode/slirp NAT, so
+// the host can't route to whatever address the guest's NIC has, only to
+// the ports slirp forwards there (see the -netdev hostfwd args in Start).
+func (p *qemuVMProvider) IP(name string) (string, error) {
+	if !p.IsRunning(name) {
+		return "", errors.NewError("VM %q is not running", name)
+	}
+	return "127.0.0.1", nil
+}
+
+// Client points a Docker client at the Docker daemon inside the VM, reached
+// through the TCP port qemu-system-x86_64 forwards to the guest's Docker
+// socket.
+func (p *qemuVMProvider) Client(name string) (dockerhelper.Interface, error) {
+	if !p.IsRunning(name) {
+		return nil, errors.NewError("VM %q is not running", name)
+	}
+	if err := os.Setenv("DOCKER_HOST", "tcp://127.0.0.1:"+qemuDockerPort); err != nil {
+		return nil, err
+	}
+	return getDockerClient(ioutil.Discard, p, "", false)
+}
+
+// Stop kills the qemu-system-x86_64 process named by the VM's pidfile,
+// leaving its disk and cloud-init seed in place for a later Start.
+func (p *qemuVMProvider) Stop(name string) error {
+	pid, err := ioutil.ReadFile(p.pidFile(name))
+	if err != nil {
+		return nil
+	}
+	return localcmd.New("kill").Args(strings.TrimSpace(string(pid))).Run()
+}
@@ -0,0 +1,59 @@
+package docker
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/localcmd"
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/openshift"
+)
+
+// sshProvisioner runs the OpenShift container on a remote host reached over
+// SSH, by pointing the Docker client at the remote daemon through Docker's
+// native SSH transport (DOCKER_HOST=ssh://user@host) rather than driving the
+// remote host through its own exec mechanism.
+type sshProvisioner struct {
+	config *CommonStartConfig
+	host   string
+}
+
+func newSSHProvisioner(c *CommonStartConfig) (*sshProvisioner, error) {
+	if len(c.SSHHost) == 0 {
+		return nil, fmt.Errorf("--ssh-host must be specified when --provider=%s", ProviderSSH)
+	}
+	return &sshProvisioner{config: c, host: c.SSHHost}, nil
+}
+
+func (p *sshProvisioner) EnsureRuntime(out io.Writer) error {
+	fmt.Fprintf(out, "Connecting to Docker daemon on %s over SSH\n", p.host)
+	if err := localcmd.New("ssh").Args(p.host, "docker", "version").Run(); err != nil {
+		return fmt.Errorf("cannot reach Docker daemon on %q over SSH: %v", p.host, err)
+	}
+	if err := os.Setenv("DOCKER_HOST", "ssh://"+p.host); err != nil {
+		return fmt.Errorf("cannot set DOCKER_HOST for %q: %v", p.host, err)
+	}
+	client, err := getDockerClient(out, nil, "", false)
+	if err != nil {
+		return fmt.Errorf("cannot get Docker client for %q: %v", p.host, err)
+	}
+	p.config.dockerClient = client
+	return p.config.CheckDockerVersion(out)
+}
+
+func (p *sshProvisioner) StartMaster(out io.Writer) error {
+	// Never called: Complete leaves c.provisioner unset for this provider, so
+	// ClientStartConfig.Start's normal StartSelfHosted path starts the master
+	// against the Docker daemon EnsureRuntime already wired up. This method
+	// exists only to satisfy ClusterProvisioner.
+	return nil
+}
+
+func (p *sshProvisioner) StartNode(out io.Writer) error {
+	return fmt.Errorf("adding nodes is not supported for --provider=%s", ProviderSSH)
+}
+
+func (p *sshProvisioner) Teardown(out io.Writer) error {
+	fmt.Fprintf(out, "Stopping OpenShift container on %s\n", p.host)
+	return p.config.DockerHelper().RemoveContainer(openshift.OpenShiftContainer)
+}
@@ -0,0 +1,89 @@
+package docker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	sysctlIPForward             = "net/ipv4/ip_forward"
+	sysctlBridgeNFCallIPTables  = "net/bridge/bridge-nf-call-iptables"
+	sysctlBridgeNFCallIP6Tables = "net/bridge/bridge-nf-call-ip6tables"
+	sysctlNFConntrackMax        = "net/netfilter/nf_conntrack_max"
+	sysctlSomaxconn             = "net/core/somaxconn"
+
+	// minConntrackMax and minSomaxconn are the lowest values 'cluster up'
+	// considers safe for SDN pod scale and Kubernetes service connection
+	// backlogs respectively; below them, pod networking and service
+	// connections degrade in ways that only show up after the cluster is
+	// already running.
+	minConntrackMax = 131072
+	minSomaxconn    = 512
+)
+
+// Sysctl reads the current value of a kernel parameter under /proc/sys,
+// e.g. Sysctl("net/ipv4/ip_forward").
+func Sysctl(path string) (int, error) {
+	data, err := ioutil.ReadFile(filepath.Join("/proc/sys", path))
+	if err != nil {
+		return 0, err
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected value for /proc/sys/%s: %q", path, strings.TrimSpace(string(data)))
+	}
+	return value, nil
+}
+
+// SetSysctl writes a corrected value for a kernel parameter under /proc/sys,
+// mirroring how kube-proxy's conntrack tuner corrects the same kind of
+// preflight problems at node startup.
+func SetSysctl(path string, value int) error {
+	return ioutil.WriteFile(filepath.Join("/proc/sys", path), []byte(strconv.Itoa(value)), 0644)
+}
+
+// sysctlWarnings preflights the handful of kernel parameters that, if wrong,
+// only manifest as broken pod networking once the cluster is already up:
+// IP forwarding, the bridge netfilter hooks kube-proxy's iptables rules
+// depend on, SDN-scale conntrack table size, and the Kubernetes service
+// connection backlog. It is a no-op on non-Linux hosts, since 'cluster up'
+// always runs the OpenShift container inside a Docker VM there instead of
+// directly against the host network stack. When --fix-sysctls is set, it
+// corrects what it can via SetSysctl instead of only warning.
+func (c *ClientStartConfig) sysctlWarnings() []string {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+
+	var warnings []string
+	checkMinimum := func(path string, min int, fix bool, describe string) {
+		value, err := Sysctl(path)
+		if err != nil {
+			// Not every kernel exposes every one of these knobs (e.g. a
+			// container without the bridge netfilter module loaded won't
+			// have net/bridge/* at all); skip rather than warn about it.
+			return
+		}
+		if value >= min {
+			return
+		}
+		if fix && c.FixSysctls {
+			if err := SetSysctl(path, min); err == nil {
+				return
+			}
+		}
+		warnings = append(warnings, fmt.Sprintf("%s is %d; %s", path, value, describe))
+	}
+
+	checkMinimum(sysctlIPForward, 1, true, "IP forwarding must be enabled for pod networking to work")
+	checkMinimum(sysctlBridgeNFCallIPTables, 1, true, "kube-proxy's iptables rules will be bypassed for bridged traffic")
+	checkMinimum(sysctlBridgeNFCallIP6Tables, 1, true, "kube-proxy's ip6tables rules will be bypassed for bridged traffic")
+	checkMinimum(sysctlNFConntrackMax, minConntrackMax, true, fmt.Sprintf("the conntrack table may fill up at SDN pod scale; recommended minimum is %d", minConntrackMax))
+	checkMinimum(sysctlSomaxconn, minSomaxconn, true, fmt.Sprintf("the connection backlog for Kubernetes services may be too small; recommended minimum is %d", minSomaxconn))
+
+	return warnings
+}
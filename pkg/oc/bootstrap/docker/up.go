@@ -6,9 +6,11 @@ import (
 	"io"
 	"net"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/blang/semver"
@@ -23,7 +25,9 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
 	kclientcmd "k8s.io/client-go/tools/clientcmd"
+	kclientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	kclientset "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
 	kcmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
@@ -175,18 +179,49 @@ func NewCmdUp(name, fullName string, f *osclientcmd.Factory, out, errout io.Writ
 			kcmdutil.CheckErr(config.Complete(f, c, out))
 			kcmdutil.CheckErr(config.Validate(out, errout))
 			kcmdutil.CheckErr(config.CommonStartConfig.Check(out))
-			if err := config.Start(out); err != nil {
+
+			ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer cancel()
+			if config.OverallTimeout > 0 {
+				var overallCancel context.CancelFunc
+				ctx, overallCancel = context.WithTimeout(ctx, config.OverallTimeout)
+				defer overallCancel()
+			}
+
+			if err := config.Start(ctx, out); err != nil {
 				fmt.Fprintf(errout, "%s\n", err.Error())
 				os.Exit(1)
 			}
+
+			if len(config.E2ESuite) > 0 {
+				e2eErr := config.RunE2E(out)
+				if config.E2ETeardown {
+					if provisioner, provErr := config.provisionerFor(); provErr == nil {
+						if err := provisioner.Teardown(out); err != nil {
+							fmt.Fprintf(errout, "WARNING: failed to tear down cluster after --e2e: %v\n", err)
+						}
+					}
+				}
+				if e2eErr != nil {
+					fmt.Fprintf(errout, "%s\n", e2eErr.Error())
+					os.Exit(1)
+				}
+			}
 		},
 	}
 	config.Bind(cmd.Flags())
 	return cmd
 }
 
-// taskFunc is a function that executes a start task
-type taskFunc func(io.Writer) error
+// taskFunc is a function that executes a start task. Each ClientStartConfig
+// task method below checks its ctx once at entry and returns early if it's
+// already done, but cannot thread it any deeper: the actual blocking work
+// (image pulls, installer waits) happens inside c.OpenShiftHelper() and
+// c.DockerHelper(), whose methods don't take a context. Until those take
+// one, --task-timeout and SIGINT only ever interrupt a task between entry
+// and its first helper call, never a helper call already in flight; see
+// CommonStartConfig.TaskTimeout for what the timeout can and can't do.
+type taskFunc func(context.Context, io.Writer) error
 
 // conditionFunc determines whether a task should be run on start
 type conditionFunc func() bool
@@ -213,6 +248,7 @@ type CommonStartConfig struct {
 	ImageStreams                string
 	DockerMachine               string
 	ShouldCreateDockerMachine   bool
+	VMDriver                    string
 	SkipRegistryCheck           bool
 	ShouldInstallMetrics        bool
 	ShouldInstallLogging        bool
@@ -245,6 +281,59 @@ type CommonStartConfig struct {
 	CACert                   string
 	PVCount                  int
 
+	// Provider selects the ClusterProvisioner used to bring the cluster up,
+	// e.g. "docker" (the default), "azure", or "ssh".
+	Provider string
+
+	// SSHHost is the user@host (or host) to run the OpenShift container on
+	// when --provider=ssh, reached through Docker's native SSH transport.
+	SSHHost string
+
+	// InCluster forces the in-cluster bootstrap path even when the usual
+	// auto-detection (KUBERNETES_SERVICE_HOST/PORT + ServiceAccount token)
+	// doesn't apply, e.g. when only KUBECONFIG is mounted.
+	InCluster bool
+
+	// NodeCount and NodePools control how many additional node containers
+	// are started on the local Docker daemon beyond the single master.
+	// NodePools further splits the node containers into named,
+	// independently labeled pools. NodeCount defaults to 0: starting
+	// additional node containers is not implemented for --provider=docker
+	// yet, so the flags are opt-in only and fail loudly rather than
+	// silently doing nothing. There is no --master-count: this provider
+	// only ever starts a single master container.
+	NodeCount     int
+	NodeLabels    string
+	NodePoolSpecs []string
+
+	// TaskTimeout bounds a single start task; OverallTimeout bounds the whole
+	// Start call. Either can be disabled by passing 0. Neither actually
+	// preempts a task's in-flight work: no task threads its ctx into the
+	// blocking calls it makes (OpenShiftHelper, Docker, HTTP), only checks it
+	// at entry, so a hung task still runs to completion. What the timeout
+	// does provide is detection: runTask reports a task that overran its
+	// deadline as cancelled even if it eventually returned success, so a
+	// stuck step is surfaced as a timeout error instead of silently passing.
+	TaskTimeout    time.Duration
+	OverallTimeout time.Duration
+
+	// Output selects how start progress is reported in addition to the
+	// human-readable printer: "" (the default) adds nothing, "json" adds a
+	// JSON-lines event stream on out.
+	Output string
+	// ProgressHTTPAddr, if non-empty, serves the same event stream as JSON
+	// over HTTP on this address (e.g. "127.0.0.1:0" for an ephemeral port) so
+	// a wrapper UI or supervising job can watch 'cluster up' in real time.
+	ProgressHTTPAddr string
+
+	// Resume skips any task whose checkpoint entry completed successfully
+	// with the same inputs it would run with now; see taskInputHash and
+	// resumableTask. ForceTask names a single task to always re-run even
+	// when Resume is set, e.g. to retry a step whose output is now stale for
+	// reasons its inputs don't capture.
+	Resume    bool
+	ForceTask string
+
 	dockerClient    dockerhelper.Interface
 	dockerHelper    *dockerhelper.Helper
 	hostHelper      *host.HostHelper
@@ -252,6 +341,11 @@ type CommonStartConfig struct {
 	factory         *clientcmd.Factory
 	originalFactory *clientcmd.Factory
 	command         *cobra.Command
+	provisioner     ClusterProvisioner
+	eventBus        *eventBus
+
+	resolvedNodeLabels map[string]string
+	resolvedNodePools  []NodePoolSpec
 
 	usingDefaultImages         bool
 	usingDefaultOpenShiftImage bool
@@ -271,6 +365,7 @@ func (c *CommonStartConfig) addTask(t task) {
 func (config *CommonStartConfig) Bind(flags *pflag.FlagSet) {
 	flags.BoolVar(&config.ShouldCreateDockerMachine, "create-machine", false, "Create a Docker machine if one doesn't exist")
 	flags.StringVar(&config.DockerMachine, "docker-machine", "", "Specify the Docker machine to use")
+	flags.StringVar(&config.VMDriver, "vm-driver", "", fmt.Sprintf("VM provider to back --docker-machine/--create-machine with: %s|%s", VMDriverDockerMachine, VMDriverQEMU))
 	flags.StringVar(&config.ImageVersion, "version", "", "Specify the tag for OpenShift images")
 	flags.StringVar(&config.Image, "image", variable.DefaultImagePrefix, "Specify the images to use for OpenShift")
 	flags.StringVar(&config.ImageStreams, "image-streams", defaultImageStreams, "Specify which image streams to use, centos7|rhel7")
@@ -292,34 +387,128 @@ func (config *CommonStartConfig) Bind(flags *pflag.FlagSet) {
 	flags.StringVar(&config.HTTPProxy, "http-proxy", "", "HTTP proxy to use for master and builds")
 	flags.StringVar(&config.HTTPSProxy, "https-proxy", "", "HTTPS proxy to use for master and builds")
 	flags.StringArrayVar(&config.NoProxy, "no-proxy", config.NoProxy, "List of hosts or subnets for which a proxy should not be used")
+	flags.StringVar(&config.Provider, "provider", ProviderDocker, "Specify the provisioner to bring up the cluster with, docker|azure|ssh")
+	flags.StringVar(&config.SSHHost, "ssh-host", "", "user@host to run the OpenShift container on when --provider=ssh")
+	flags.BoolVar(&config.InCluster, "in-cluster", false, "Run the bootstrap tasks against the cluster oc is currently running in, instead of starting a local Docker container")
+	flags.IntVar(&config.NodeCount, "node-count", 0, "Number of node containers to start in addition to the master; any value above 0 makes cluster up fail, as no provider starts additional nodes")
+	flags.StringVar(&config.NodeLabels, "node-labels", "", "Comma-separated key=value labels to apply to every started node")
+	flags.StringArrayVar(&config.NodePoolSpecs, "node-pool", []string{}, "Repeatable name=count[,label=value,...] node pool to start, e.g. infra=2,role=infra; any pool makes cluster up fail, as no provider starts additional nodes")
+	flags.DurationVar(&config.TaskTimeout, "task-timeout", 5*time.Minute, "Maximum time to wait for any single start task to complete before reporting it as timed out, 0 to disable; does not interrupt a task already in flight")
+	flags.DurationVar(&config.OverallTimeout, "overall-timeout", 0, "Maximum time to wait for the entire cluster up to complete before reporting it as timed out, 0 to disable; does not interrupt a task already in flight")
+	flags.StringVar(&config.Output, "output", "", "Emit machine-readable start progress in addition to the normal output: json")
+	flags.StringVar(&config.ProgressHTTPAddr, "progress-http-addr", "", "Serve start progress as JSON over HTTP on this address, e.g. 127.0.0.1:0 for an ephemeral port")
+	flags.BoolVar(&config.Resume, "resume", false, "Skip tasks that completed successfully with unchanged inputs on a previous run, retrying only failed or invalidated ones")
+	flags.StringVar(&config.ForceTask, "force-task", "", "Force a specific task to re-run even with --resume, e.g. --force-task='Installing router'")
 }
 
 // Start runs the start tasks ensuring that they are executed in sequence
-func (c *CommonStartConfig) Start(out io.Writer) error {
-	taskPrinter := NewTaskPrinter(out)
+func (c *CommonStartConfig) Start(ctx context.Context, out io.Writer) error {
+	printer := NewTaskPrinter(out)
+	bus, closer, err := c.newEventBus(out, printer)
+	if err != nil {
+		return err
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+	c.eventBus = bus
+	checkpoint, err := c.loadCheckpoint()
+	if err != nil {
+		return err
+	}
 	for _, task := range c.Tasks {
 		if task.condition != nil && !task.condition() {
 			continue
 		}
-		taskPrinter.StartTask(task.name)
-		w := taskPrinter.TaskWriter()
-		err := task.fn(w)
+		if entry, ok := c.resumableTask(checkpoint, task.name); ok {
+			bus.Publish(TaskStarted{Task: task.name})
+			fmt.Fprintf(printer.TaskWriter(), "skipping, unchanged since it completed at %s\n", entry.CompletedAt)
+			bus.Publish(TaskSucceeded{Task: task.name})
+			continue
+		}
+		bus.Publish(TaskStarted{Task: task.name})
+		var taskLog bytes.Buffer
+		w := io.MultiWriter(printer.TaskWriter(), &taskLog)
+		start := time.Now()
+		err := c.runTask(ctx, task, w)
+		if recordErr := c.recordTaskResult(checkpoint, task.name, err == nil, taskLog.String()); recordErr != nil {
+			fmt.Fprintf(out, "warning: cannot persist checkpoint: %v\n", recordErr)
+		}
 		if err != nil {
-			taskPrinter.Failure(err)
+			bus.Publish(newTaskFailed(task.name, err))
 			return err
 		}
-		taskPrinter.Success()
+		bus.Publish(TaskSucceeded{Task: task.name, Duration: time.Since(start)})
 	}
 	return nil
 }
 
+// runTask executes a single task's taskFunc, applying --task-timeout (if
+// set) and reporting which task overran its deadline. This is detection,
+// not preemption: no task threads taskCtx into the blocking calls it makes
+// (OpenShiftHelper, Docker, HTTP), only checks it once at entry, so a task
+// that hangs past the deadline keeps running to completion regardless. The
+// taskCtx.Err() check below runs unconditionally, not just when t.fn
+// returns an error, so a task that overran the deadline but still reported
+// success is still surfaced as cancelled rather than silently treated as a
+// pass.
+func (c *CommonStartConfig) runTask(ctx context.Context, t task, w io.Writer) error {
+	taskCtx := ctx
+	if c.TaskTimeout > 0 {
+		var cancel context.CancelFunc
+		taskCtx, cancel = context.WithTimeout(ctx, c.TaskTimeout)
+		defer cancel()
+	}
+	err := t.fn(taskCtx, w)
+	if taskCtx.Err() != nil {
+		return fmt.Errorf("task %q was cancelled: %v", t.name, taskCtx.Err())
+	}
+	return err
+}
+
 // ClientStartConfig is the configuration for the client start command
 type ClientStartConfig struct {
 	CommonStartConfig
+
+	// E2ESuite, when non-empty, runs a curated Ginkgo e2e suite against the
+	// cluster once Start succeeds. See RunE2E.
+	E2ESuite        string
+	E2ETimeout      time.Duration
+	E2EArtifactsDir string
+	E2EFocus        string
+	E2ESkip         string
+	E2ETeardown     bool
+
+	// Verify, when not "none", runs VerifyCluster as the last start task. See
+	// VerifyCluster.
+	Verify string
+
+	// FixSysctls corrects kernel parameters sysctlWarnings flags as unsafe,
+	// instead of only warning about them.
+	FixSysctls bool
+
+	// Kubeconfig, when set, is loaded by Factory() instead of the generated
+	// admin.kubeconfig under LocalConfigDir, e.g. to re-run against a
+	// cluster whose local config dir has been wiped but whose admin
+	// credentials were merged into a developer kubeconfig. Context selects
+	// which of its contexts to use; left empty, the kubeconfig's own
+	// CurrentContext is used as-is.
+	Kubeconfig string
+	Context    string
 }
 
 func (config *ClientStartConfig) Bind(flags *pflag.FlagSet) {
 	config.CommonStartConfig.Bind(flags)
+	flags.StringVar(&config.E2ESuite, "e2e", "", "Run a curated e2e suite against the cluster after it starts: smoke|conformance|networking|openshift")
+	flags.DurationVar(&config.E2ETimeout, "e2e-timeout", 30*time.Minute, "Maximum time to let the --e2e suite run")
+	flags.StringVar(&config.E2EArtifactsDir, "e2e-artifacts-dir", "", "Directory to write e2e JUnit XML and logs to, defaults under the local config dir")
+	flags.StringVar(&config.E2EFocus, "e2e-focus", "", "Override the Ginkgo focus regex for the selected --e2e suite")
+	flags.StringVar(&config.E2ESkip, "e2e-skip", "", "Override the Ginkgo skip regex for the selected --e2e suite")
+	flags.BoolVar(&config.E2ETeardown, "e2e-teardown", false, "Tear down the cluster after the --e2e suite completes")
+	flags.StringVar(&config.Verify, "verify", VerifyNone, "Verify the cluster works by exercising a sample app through it after it starts: smoke|conformance|none")
+	flags.BoolVar(&config.FixSysctls, "fix-sysctls", false, "Correct unsafe kernel parameters found by the preflight sysctl check instead of only warning about them")
+	flags.StringVar(&config.Kubeconfig, "kubeconfig", "", "Use an existing kubeconfig instead of the generated admin.kubeconfig")
+	flags.StringVar(&config.Context, "context", "", "Context to use from --kubeconfig; defaults to its current context")
 }
 
 func (c *CommonStartConfig) Complete(f *osclientcmd.Factory, cmd *cobra.Command, out io.Writer) error {
@@ -331,6 +520,54 @@ func (c *CommonStartConfig) Complete(f *osclientcmd.Factory, cmd *cobra.Command,
 		c.ImageVersion = defaultImageVersion()
 	}
 
+	nodeLabels, err := parseNodeLabels(c.NodeLabels)
+	if err != nil {
+		return err
+	}
+	c.resolvedNodeLabels = nodeLabels
+	nodePools, err := parseNodePools(c.NodePoolSpecs)
+	if err != nil {
+		return err
+	}
+	c.resolvedNodePools = nodePools
+
+	if c.isInCluster() {
+		return c.completeInCluster(out)
+	}
+
+	// Providers that don't run against a local or machine-managed Docker
+	// daemon at all (e.g. Azure) skip every Docker-specific health/version/
+	// nsenter/port check below and rely entirely on their own EnsureRuntime
+	// and StartMaster implementations instead.
+	if isFullyCustomProvider(c.Provider) {
+		provisioner, err := c.provisionerFor()
+		if err != nil {
+			return err
+		}
+		c.provisioner = provisioner
+		return provisioner.EnsureRuntime(out)
+	}
+
+	// Providers that still run the OpenShift container on a Docker daemon,
+	// just not the local one reached by plain environment variables (a
+	// remote host over SSH), wire up c.dockerClient themselves; the Docker
+	// health/version/port checks below and the normal StartSelfHosted master
+	// start later in ClientStartConfig.Start then run against that daemon
+	// exactly as they would locally. c.provisioner is deliberately left unset
+	// here so that short-circuit does not fire for these providers; only
+	// Teardown needs a provisioner, and NewCmdUp resolves one fresh via
+	// provisionerFor.
+	usesExternalDockerClient := c.Provider == ProviderSSH
+	if usesExternalDockerClient {
+		provisioner, err := c.provisionerFor()
+		if err != nil {
+			return err
+		}
+		if err := provisioner.EnsureRuntime(out); err != nil {
+			return err
+		}
+	}
+
 	// do some struct initialization next
 
 	// used for some pretty printing
@@ -353,14 +590,21 @@ func (c *CommonStartConfig) Complete(f *osclientcmd.Factory, cmd *cobra.Command,
 
 	// Get a Docker client.
 	// If a Docker machine was specified, make sure that the machine is running.
-	// Otherwise, use environment variables.
-	taskPrinter.StartTask("Create Docker client")
-	client, err := getDockerClient(out, c.DockerMachine, true)
-	if err != nil {
-		return taskPrinter.ToError(err)
+	// Otherwise, use environment variables. Providers that already populated
+	// c.dockerClient via EnsureRuntime above keep using it.
+	if !usesExternalDockerClient {
+		taskPrinter.StartTask("Create Docker client")
+		vmProvider, err := c.vmProvider()
+		if err != nil {
+			return taskPrinter.ToError(err)
+		}
+		client, err := getDockerClient(out, vmProvider, c.DockerMachine, true)
+		if err != nil {
+			return taskPrinter.ToError(err)
+		}
+		c.dockerClient = client
+		taskPrinter.Success()
 	}
-	c.dockerClient = client
-	taskPrinter.Success()
 
 	// TODO HOLY SIDE-EFFECTS this is setting global bool values as part of a check!
 	// Check that we have the minimum Docker version available to run OpenShift
@@ -461,6 +705,9 @@ func (c *CommonStartConfig) Complete(f *osclientcmd.Factory, cmd *cobra.Command,
 
 // Validate validates that required fields in StartConfig have been populated
 func (c *CommonStartConfig) Validate() error {
+	if c.isInCluster() {
+		return nil
+	}
 	if c.dockerClient == nil {
 		return fmt.Errorf("missing dockerClient")
 	}
@@ -470,6 +717,10 @@ func (c *CommonStartConfig) Validate() error {
 // Check is a spot to do NON-MUTATING, preflight checks. Over time, we should try to move our non-mutating checks out of
 // Complete and into Check.
 func (c *CommonStartConfig) Check(out io.Writer) error {
+	if c.isInCluster() {
+		return nil
+	}
+
 	// used for some pretty printing
 	taskPrinter := NewTaskPrinter(getDetailedOut(out))
 
@@ -558,8 +809,15 @@ func (c *ClientStartConfig) Complete(f *osclientcmd.Factory, cmd *cobra.Command,
 	//	c.addTask(simpleTask("Checking container networking", c.CheckContainerNetworking))
 	//}
 
-	// Display server information
-	c.addTask(simpleTask("Server Information", c.ServerInfo))
+	// Verify the cluster works end to end by exercising a sample app through it
+	c.addTask(conditionalTask("Verifying cluster", c.VerifyCluster, func() bool {
+		return c.Verify != "" && c.Verify != VerifyNone
+	}))
+
+	// Server information is printed once runTasks' loop finishes, not as a
+	// task itself: ServerInfo publishes through c.eventBus, and running it
+	// both as a task (mid-loop) and via runTasks' own post-loop call would
+	// print the "OpenShift server started" banner twice.
 
 	return nil
 }
@@ -586,9 +844,17 @@ func getDetailedOut(out io.Writer) io.Writer {
 }
 
 // Start runs the start tasks ensuring that they are executed in sequence
-func (c *ClientStartConfig) Start(out io.Writer) error {
+func (c *ClientStartConfig) Start(ctx context.Context, out io.Writer) error {
 	fmt.Fprintf(out, "Starting OpenShift using %s ...\n", c.openshiftImage())
 
+	if c.provisioner != nil {
+		return c.provisioner.StartMaster(out)
+	}
+
+	if c.isInCluster() {
+		return c.runTasks(ctx, out)
+	}
+
 	if c.PortForwarding {
 		if err := c.OpenShiftHelper().StartSocatTunnel(c.ServerIP); err != nil {
 			return err
@@ -598,6 +864,15 @@ func (c *ClientStartConfig) Start(out io.Writer) error {
 	if err := c.StartSelfHosted(out); err != nil {
 		return err
 	}
+	if len(c.additionalNodeNames()) > 0 {
+		provisioner, err := c.provisionerFor()
+		if err != nil {
+			return err
+		}
+		if err := provisioner.StartNode(out); err != nil {
+			return err
+		}
+	}
 	if c.WriteConfig {
 		return nil
 	}
@@ -605,24 +880,56 @@ func (c *ClientStartConfig) Start(out io.Writer) error {
 		return err
 	}
 
+	return c.runTasks(ctx, out)
+}
+
+// runTasks executes c.Tasks in sequence (registry, router, image streams,
+// web console, etc.), buffering their detailed output unless -v is set, and
+// prints ServerInfo on success. It is shared by the normal Docker start path
+// and the in-cluster bootstrap path, which both populate c.Tasks the same
+// way in Complete.
+func (c *ClientStartConfig) runTasks(ctx context.Context, out io.Writer) error {
 	detailedOut := getDetailedOut(out)
-	taskPrinter := NewTaskPrinter(detailedOut)
+	printer := NewTaskPrinter(detailedOut)
+	bus, closer, err := c.newEventBus(out, printer)
+	if err != nil {
+		return err
+	}
+	if closer != nil {
+		defer closer.Close()
+	}
+	c.eventBus = bus
 	startError := func() error {
+		checkpoint, err := c.loadCheckpoint()
+		if err != nil {
+			return err
+		}
 		for _, task := range c.Tasks {
 			if task.condition != nil && !task.condition() {
 				continue
 			}
-			taskPrinter.StartTask(task.name)
-			w := taskPrinter.TaskWriter()
+			if entry, ok := c.resumableTask(checkpoint, task.name); ok {
+				bus.Publish(TaskStarted{Task: task.name})
+				fmt.Fprintf(printer.TaskWriter(), "skipping, unchanged since it completed at %s\n", entry.CompletedAt)
+				bus.Publish(TaskSucceeded{Task: task.name})
+				continue
+			}
+			bus.Publish(TaskStarted{Task: task.name})
+			var taskLog bytes.Buffer
+			w := io.MultiWriter(printer.TaskWriter(), &taskLog)
 			if task.stdOut && !bool(glog.V(1)) {
 				w = io.MultiWriter(w, out)
 			}
-			err := task.fn(w)
+			start := time.Now()
+			err := c.runTask(ctx, task, w)
+			if recordErr := c.recordTaskResult(checkpoint, task.name, err == nil, taskLog.String()); recordErr != nil {
+				fmt.Fprintf(out, "warning: cannot persist checkpoint: %v\n", recordErr)
+			}
 			if err != nil {
-				taskPrinter.Failure(err)
+				bus.Publish(newTaskFailed(task.name, err))
 				return err
 			}
-			taskPrinter.Success()
+			bus.Publish(TaskSucceeded{Task: task.name, Duration: time.Since(start)})
 		}
 		return nil
 	}()
@@ -633,7 +940,7 @@ func (c *ClientStartConfig) Start(out io.Writer) error {
 		return startError
 	}
 	if !bool(glog.V(1)) {
-		c.ServerInfo(out)
+		c.ServerInfo(ctx, out)
 	}
 	return nil
 }
@@ -703,10 +1010,13 @@ func (c *CommonStartConfig) GetDockerClient(out io.Writer) dockerhelper.Interfac
 
 // getDockerClient obtains a new Docker client from the environment or
 // from a Docker machine, starting it if necessary and permitted
-func getDockerClient(out io.Writer, dockerMachine string, canStartDockerMachine bool) (dockerhelper.Interface, error) {
+func getDockerClient(out io.Writer, provider VMProvider, dockerMachine string, canStartDockerMachine bool) (dockerhelper.Interface, error) {
 	if len(dockerMachine) > 0 {
+		if provider == nil {
+			provider = dockerMachineProvider{}
+		}
 		glog.V(2).Infof("Getting client for Docker machine %q", dockerMachine)
-		client, err := getDockerMachineClient(dockerMachine, out, canStartDockerMachine)
+		client, err := getDockerMachineClient(provider, dockerMachine, out, canStartDockerMachine)
 		if err != nil {
 			return nil, errors.ErrNoDockerMachineClient(dockerMachine, err)
 		}
@@ -849,12 +1159,36 @@ func checkPortForwardingPrerequisites(out io.Writer) error {
 	return nil
 }
 
-func (c *CommonStartConfig) EnsureHostDirectories(io.Writer) error {
+// EnsureHostDirectories creates the host directories the single master
+// container mounts. It does not namespace directories per additionalNodeNames
+// entry: StartNode doesn't actually start those containers yet (see
+// dockerProvisioner.StartNode), so there is nothing that would ever use a
+// per-node directory.
+func (c *CommonStartConfig) EnsureHostDirectories(out io.Writer) error {
 	return c.HostHelper().EnsureHostDirectories(!c.UseNsenterMount)
 }
 
+// additionalNodeNames returns the names of every node container beyond the
+// single master, derived from --node-count and --node-pool. Plain nodes are
+// named "node-N"; pool members are named "<pool>-N".
+func (c *CommonStartConfig) additionalNodeNames() []string {
+	names := []string{}
+	for i := 0; i < c.NodeCount; i++ {
+		names = append(names, fmt.Sprintf("node-%d", i+1))
+	}
+	for _, pool := range c.resolvedNodePools {
+		for i := 0; i < pool.Count; i++ {
+			names = append(names, fmt.Sprintf("%s-%d", pool.Name, i+1))
+		}
+	}
+	return names
+}
+
 // EnsureDefaultRedirectURIs merges a default URL to an auth client's RedirectURIs array
-func (c *ClientStartConfig) EnsureDefaultRedirectURIs(out io.Writer) error {
+func (c *ClientStartConfig) EnsureDefaultRedirectURIs(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	factory, err := c.Factory()
 	if err != nil {
 		return err
@@ -1025,7 +1359,10 @@ func (c *CommonStartConfig) imageFormat() string {
 }
 
 // InstallRegistry installs the OpenShift registry on the server
-func (c *ClientStartConfig) InstallRegistry(out io.Writer) error {
+func (c *ClientStartConfig) InstallRegistry(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	_, kubeClient, err := c.Clients()
 	if err != nil {
 		return err
@@ -1038,7 +1375,10 @@ func (c *ClientStartConfig) InstallRegistry(out io.Writer) error {
 }
 
 // InstallRouter installs a default router on the server
-func (c *ClientStartConfig) InstallRouter(out io.Writer) error {
+func (c *ClientStartConfig) InstallRouter(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	_, kubeClient, err := c.Clients()
 	if err != nil {
 		return err
@@ -1051,7 +1391,10 @@ func (c *ClientStartConfig) InstallRouter(out io.Writer) error {
 }
 
 // InstallWebConsole installs the OpenShift web console on the server
-func (c *ClientStartConfig) InstallWebConsole(out io.Writer) error {
+func (c *ClientStartConfig) InstallWebConsole(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	f, err := c.Factory()
 	if err != nil {
 		return err
@@ -1079,7 +1422,10 @@ func (c *ClientStartConfig) InstallWebConsole(out io.Writer) error {
 
 // ImportImageStreams imports default image streams into the server
 // TODO: Use streams compiled into oc
-func (c *ClientStartConfig) ImportImageStreams(out io.Writer) error {
+func (c *ClientStartConfig) ImportImageStreams(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	imageStreamLocations := map[string]string{
 		c.ImageStreams: imageStreams[c.ImageStreams],
 	}
@@ -1088,7 +1434,10 @@ func (c *ClientStartConfig) ImportImageStreams(out io.Writer) error {
 
 // ImportTemplates imports default templates into the server
 // TODO: Use templates compiled into oc
-func (c *ClientStartConfig) ImportTemplates(out io.Writer) error {
+func (c *ClientStartConfig) ImportTemplates(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if err := c.importObjects(out, openshift.OpenshiftNamespace, templateLocations); err != nil {
 		return err
 	}
@@ -1103,7 +1452,10 @@ func (c *ClientStartConfig) ImportTemplates(out io.Writer) error {
 }
 
 // ImportInternalTemplates imports internal system templates into the server
-func (c *ClientStartConfig) ImportInternalTemplates(out io.Writer) error {
+func (c *ClientStartConfig) ImportInternalTemplates(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if err := c.importObjects(out, openshift.OpenshiftInfraNamespace, internalTemplateLocations); err != nil {
 		return err
 	}
@@ -1126,7 +1478,10 @@ func (c *ClientStartConfig) ImportInternalTemplates(out io.Writer) error {
 }
 
 // ImportLoggingTemplates imports service catalog templates into the server
-func (c *ClientStartConfig) ImportLoggingTemplates(out io.Writer) error {
+func (c *ClientStartConfig) ImportLoggingTemplates(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if err := c.importObjects(out, openshift.OpenshiftInfraNamespace, loggingTemplateLocations); err != nil {
 		return err
 	}
@@ -1149,7 +1504,10 @@ func clusterVersionIsCurrent(v semver.Version) bool {
 }
 
 // InstallLogging will start the installation of logging components
-func (c *ClientStartConfig) InstallLogging(out io.Writer) error {
+func (c *ClientStartConfig) InstallLogging(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	f, err := c.Factory()
 	if err != nil {
 		return err
@@ -1174,7 +1532,10 @@ func (c *ClientStartConfig) InstallLogging(out io.Writer) error {
 }
 
 // InstallMetrics will start the installation of Metrics components
-func (c *ClientStartConfig) InstallMetrics(out io.Writer) error {
+func (c *ClientStartConfig) InstallMetrics(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	f, err := c.Factory()
 	if err != nil {
 		return err
@@ -1199,7 +1560,10 @@ func (c *ClientStartConfig) InstallMetrics(out io.Writer) error {
 }
 
 // InstallServiceCatalog will start the installation of service catalog components
-func (c *ClientStartConfig) InstallServiceCatalog(out io.Writer) error {
+func (c *ClientStartConfig) InstallServiceCatalog(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	f, err := c.Factory()
 	if err != nil {
 		return err
@@ -1212,7 +1576,10 @@ func (c *ClientStartConfig) InstallServiceCatalog(out io.Writer) error {
 }
 
 // InstallTemplateServiceBroker will start the installation of template service broker
-func (c *ClientStartConfig) InstallTemplateServiceBroker(out io.Writer) error {
+func (c *ClientStartConfig) InstallTemplateServiceBroker(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	f, err := c.Factory()
 	if err != nil {
 		return err
@@ -1234,7 +1601,10 @@ func (c *ClientStartConfig) InstallTemplateServiceBroker(out io.Writer) error {
 }
 
 // RegisterTemplateServiceBroker will register the tsb with the service catalog
-func (c *ClientStartConfig) RegisterTemplateServiceBroker(out io.Writer) error {
+func (c *ClientStartConfig) RegisterTemplateServiceBroker(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	f, err := c.Factory()
 	if err != nil {
 		return err
@@ -1243,13 +1613,19 @@ func (c *ClientStartConfig) RegisterTemplateServiceBroker(out io.Writer) error {
 }
 
 // Login logs into the new server and sets up a default user and project
-func (c *ClientStartConfig) Login(out io.Writer) error {
+func (c *ClientStartConfig) Login(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	server := c.OpenShiftHelper().Master(c.ServerIP)
 	return openshift.Login(initialUser, initialPassword, server, c.LocalConfigDir, c.originalFactory, c.command, out, out)
 }
 
 // CreateProject creates a new project for the current user
-func (c *ClientStartConfig) CreateProject(out io.Writer) error {
+func (c *ClientStartConfig) CreateProject(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	f, err := openshift.LoggedInUserFactory()
 	if err != nil {
 		return errors.NewError("cannot get logged in user client").WithCause(err)
@@ -1257,47 +1633,53 @@ func (c *ClientStartConfig) CreateProject(out io.Writer) error {
 	return openshift.CreateProject(f, initialProjectName, initialProjectDisplay, initialProjectDesc, "oc", out)
 }
 
-// ServerInfo displays server information after a successful start
-func (c *ClientStartConfig) ServerInfo(out io.Writer) error {
-	metricsInfo := ""
-	if c.ShouldInstallMetrics && c.ShouldInitializeData() {
-		metricsInfo = fmt.Sprintf("The metrics service is available at:\n"+
-			"    https://%s/hawkular/metrics\n\n", openshift.MetricsHost(c.RoutingSuffix, c.ServerIP))
-	}
-	loggingInfo := ""
-	if c.ShouldInstallLogging && c.ShouldInitializeData() {
-		loggingInfo = fmt.Sprintf("The kibana logging UI is available at:\n"+
-			"    https://%s\n\n", openshift.LoggingHost(c.RoutingSuffix, c.ServerIP))
-	}
+// ServerInfo builds a ClusterReady event describing the finished cluster and
+// publishes it on c.eventBus, carrying the same fields the human-readable
+// printer subscriber used to format directly into text (see
+// printerSubscriber.HandleEvent). When no event bus is wired up (e.g. a
+// caller driving ServerInfo directly outside of Start/runTasks), it falls
+// back to writing the formatted text straight to out.
+func (c *ClientStartConfig) ServerInfo(ctx context.Context, out io.Writer) error {
 	masterURL := c.OpenShiftHelper().Master(c.ServerIP)
 	if len(c.PublicHostname) > 0 {
 		masterURL = fmt.Sprintf("https://%s:8443", c.PublicHostname)
 	}
-	msg := fmt.Sprintf("OpenShift server started.\n\n"+
-		"The server is accessible via web console at:\n"+
-		"    %s\n\n%s%s", masterURL, metricsInfo, loggingInfo)
 
+	ev := ClusterReady{MasterURL: masterURL}
+	if c.ShouldInstallMetrics && c.ShouldInitializeData() {
+		ev.MetricsURL = fmt.Sprintf("https://%s/hawkular/metrics", openshift.MetricsHost(c.RoutingSuffix, c.ServerIP))
+	}
+	if c.ShouldInstallLogging && c.ShouldInitializeData() {
+		ev.LoggingURL = fmt.Sprintf("https://%s", openshift.LoggingHost(c.RoutingSuffix, c.ServerIP))
+	}
 	if c.ShouldCreateUser() {
-		msg += fmt.Sprintf("You are logged in as:\n"+
-			"    User:     %s\n"+
-			"    Password: <any value>\n\n", initialUser)
-		msg += "To login as administrator:\n" +
-			"    oc login -u system:admin\n\n"
+		ev.LoggedInUser = initialUser
+	}
+	ev.AdditionalContainers = c.additionalNodeNames()
+	warnings, err := c.proxyWarnings()
+	if err != nil {
+		ev.ProxyWarnings = []string{"Unexpected error: " + err.Error()}
+	} else {
+		ev.ProxyWarnings = warnings
 	}
 
-	msg += c.checkProxySettings()
-
-	fmt.Fprintf(out, msg)
+	if c.eventBus != nil {
+		c.eventBus.Publish(ev)
+		return nil
+	}
+	fmt.Fprint(out, formatClusterReady(ev))
 	return nil
 }
 
-// checkProxySettings compares proxy settings specified for cluster up
-// and those on the Docker daemon and generates appropriate warnings.
-func (c *ClientStartConfig) checkProxySettings() string {
+// proxyWarnings compares proxy settings specified for cluster up and those on
+// the Docker daemon, and preflights host kernel parameters that commonly
+// break pod networking, returning appropriate warnings for each. Used to
+// populate ClusterReady.ProxyWarnings for every event subscriber.
+func (c *ClientStartConfig) proxyWarnings() ([]string, error) {
 	warnings := []string{}
 	dockerHTTPProxy, dockerHTTPSProxy, dockerNoProxy, err := c.DockerHelper().GetDockerProxySettings()
 	if err != nil {
-		return "Unexpected error: " + err.Error()
+		return nil, err
 	}
 	// Check HTTP proxy
 	if len(c.HTTPProxy) > 0 && len(dockerHTTPProxy) == 0 {
@@ -1326,31 +1708,73 @@ func (c *ClientStartConfig) checkProxySettings() string {
 		}
 	}
 
-	if len(warnings) > 0 {
-		buf := &bytes.Buffer{}
-		for _, w := range warnings {
-			fmt.Fprintf(buf, "WARNING: %s\n", w)
-		}
-		return buf.String()
-	}
-	return ""
+	warnings = append(warnings, c.sysctlWarnings()...)
+
+	return warnings, nil
 }
 
 // Factory returns a command factory that works with OpenShift server's admin credentials
 func (c *ClientStartConfig) Factory() (*clientcmd.Factory, error) {
 	if c.factory == nil {
-		cfg, err := kclientcmd.LoadFromFile(filepath.Join(c.LocalConfigDir, "master", "admin.kubeconfig"))
+		defaultCfg, err := c.clientConfig()
 		if err != nil {
 			return nil, err
 		}
-		overrides := &kclientcmd.ConfigOverrides{}
-		overrides.ClusterInfo.Server = fmt.Sprintf("https://%s:8443", c.ServerIP)
-		defaultCfg := kclientcmd.NewDefaultClientConfig(*cfg, overrides)
 		c.factory = clientcmd.NewFactory(defaultCfg)
 	}
 	return c.factory, nil
 }
 
+// clientConfig builds the config Factory() wraps. Normally that is the
+// generated admin.kubeconfig; when isInCluster() is true there is no such
+// file, so it falls back to the ServiceAccount credentials Kubernetes
+// mounts into the pod, surfaced the same way client-go's in-cluster
+// clients are built elsewhere, unless --kubeconfig or the KUBECONFIG
+// environment variable names a mounted kubeconfig, which is then loaded
+// instead. Outside the cluster, --kubeconfig points it at an arbitrary
+// kubeconfig instead, e.g. a merged developer kubeconfig from a previous
+// 'cluster up', with --context selecting which of its contexts to use.
+func (c *ClientStartConfig) clientConfig() (kclientcmd.ClientConfig, error) {
+	if c.isInCluster() {
+		mountedKubeconfig := c.Kubeconfig
+		if len(mountedKubeconfig) == 0 {
+			mountedKubeconfig = os.Getenv("KUBECONFIG")
+		}
+		if len(mountedKubeconfig) > 0 {
+			cfg, err := kclientcmd.LoadFromFile(mountedKubeconfig)
+			if err != nil {
+				return nil, errors.NewError("cannot load mounted kubeconfig %q", mountedKubeconfig).WithCause(err)
+			}
+			return kclientcmd.NewDefaultClientConfig(*cfg, &kclientcmd.ConfigOverrides{CurrentContext: c.Context}), nil
+		}
+		clusterConfig, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, errors.NewError("cannot load in-cluster configuration").WithCause(err)
+		}
+		overrides := &kclientcmd.ConfigOverrides{}
+		overrides.ClusterInfo.Server = clusterConfig.Host
+		overrides.ClusterInfo.CertificateAuthority = clusterConfig.TLSClientConfig.CAFile
+		overrides.AuthInfo.Token = clusterConfig.BearerToken
+		return kclientcmd.NewDefaultClientConfig(*kclientcmdapi.NewConfig(), overrides), nil
+	}
+
+	kubeconfigPath := c.Kubeconfig
+	if len(kubeconfigPath) == 0 {
+		kubeconfigPath = filepath.Join(c.LocalConfigDir, "master", "admin.kubeconfig")
+	}
+	cfg, err := kclientcmd.LoadFromFile(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	overrides := &kclientcmd.ConfigOverrides{}
+	if len(c.Context) > 0 {
+		overrides.CurrentContext = c.Context
+	} else if len(c.Kubeconfig) == 0 {
+		overrides.ClusterInfo.Server = fmt.Sprintf("https://%s:8443", c.ServerIP)
+	}
+	return kclientcmd.NewDefaultClientConfig(*cfg, overrides), nil
+}
+
 // Clients returns clients for OpenShift and Kube
 // FIXME: Refactor this to KubernetesInternal() call.
 func (c *ClientStartConfig) Clients() (interface{}, kclientset.Interface, error) {
@@ -1408,18 +1832,22 @@ func (c *CommonStartConfig) openshiftImage() string {
 	return fmt.Sprintf("%s:%s", c.Image, c.ImageVersion)
 }
 
-func getDockerMachineClient(machine string, out io.Writer, canStart bool) (dockerhelper.Interface, error) {
-	if !dockermachine.IsRunning(machine) && canStart {
+func getDockerMachineClient(provider VMProvider, machine string, out io.Writer, canStart bool) (dockerhelper.Interface, error) {
+	if !provider.IsRunning(machine) && canStart {
 		fmt.Fprintf(out, "Starting Docker machine '%s'\n", machine)
-		err := dockermachine.Start(machine)
+		err := provider.Start(machine)
 		if err != nil {
 			return nil, errors.NewError("cannot start Docker machine %q", machine).WithCause(err)
 		}
 		fmt.Fprintf(out, "Started Docker machine '%s'\n", machine)
 	}
-	return dockermachine.Client(machine)
+	return provider.Client(machine)
 }
 
+// determineAdditionalIPs doesn't need a VMProvider: it discovers extra IPs
+// the OpenShift server should be reachable on by testing interfaces the
+// Docker client already has access to, the same way regardless of which
+// VMProvider produced that client.
 func (c *CommonStartConfig) determineAdditionalIPs(ip string) ([]string, error) {
 	additionalIPs := sets.NewString()
 	serverIPs, err := c.OpenShiftHelper().OtherIPs(ip)
@@ -1471,9 +1899,13 @@ func (c *CommonStartConfig) determineIP(out io.Writer) (string, error) {
 		// If a docker machine is specified, port forwarding will not be used
 		c.PortForwarding = false
 		glog.V(2).Infof("Using docker machine %q to determine server IP", c.DockerMachine)
-		ip, err := dockermachine.IP(c.DockerMachine)
+		provider, err := c.vmProvider()
+		if err != nil {
+			return "", err
+		}
+		ip, err := provider.IP(c.DockerMachine)
 		if err != nil {
-			return "", errors.NewError("could not determine IP address").WithCause(err).WithSolution("Ensure that docker-machine is functional.")
+			return "", errors.NewError("could not determine IP address").WithCause(err).WithSolution("Ensure that the selected --vm-driver is functional.")
 		}
 		fmt.Fprintf(out, "Using docker-machine IP %s as the host IP\n", ip)
 		return ip, nil
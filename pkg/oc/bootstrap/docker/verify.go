@@ -0,0 +1,208 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/errors"
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/localcmd"
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/openshift"
+)
+
+const (
+	// VerifyNone disables the post-startup verification task.
+	VerifyNone = "none"
+	// VerifySmoke runs a short set of checks that a cluster is minimally usable.
+	VerifySmoke = "smoke"
+	// VerifyConformance runs the smoke checks plus service-catalog/TSB checks.
+	VerifyConformance = "conformance"
+
+	verifyProjectName = "cluster-verify"
+	verifySampleImage = "openshift/hello-openshift:latest"
+)
+
+// verifyCheck is one named step of the post-startup verification task.
+type verifyCheck struct {
+	name string
+	fn   func(out io.Writer) error
+}
+
+// VerifyCluster exercises a freshly started cluster end to end: it creates a
+// project, deploys a sample app from the verifySampleImage Docker image (no
+// build is run), exposes it through a route via the just-installed router,
+// requests that route from the host running 'oc cluster up' (not from inside
+// the cluster network), and round-trips an image through the registry by
+// pushing it and pulling it back. When --verify=conformance, it also confirms service catalog
+// and the template service broker registered successfully. Results are
+// written as JUnit XML plus per-check logs under LocalConfigDir/verify so CI
+// systems consuming 'oc cluster up' can gate on them.
+func (c *ClientStartConfig) VerifyCluster(ctx context.Context, out io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	verifyDir := filepath.Join(c.LocalConfigDir, "verify")
+	if err := os.MkdirAll(verifyDir, 0755); err != nil {
+		return errors.NewError("cannot create verify output directory %q", verifyDir).WithCause(err)
+	}
+
+	kubeConfig := filepath.Join(c.LocalConfigDir, "master", "admin.kubeconfig")
+	os.Setenv("KUBECONFIG", kubeConfig)
+
+	checks := []verifyCheck{
+		{"create project", c.verifyCreateProject},
+		{"deploy sample app", c.verifyBuildAndDeploy},
+		{"expose route via router", c.verifyRoute},
+		{"reach route from host", c.verifyRouteReachable},
+		{"push and pull through registry", c.verifyRegistry},
+	}
+	if c.Verify == VerifyConformance {
+		checks = append(checks,
+			verifyCheck{"service catalog registered", c.verifyServiceCatalog},
+			verifyCheck{"template service broker registered", c.verifyTemplateServiceBroker},
+		)
+	}
+
+	suite := junitTestSuite{Name: fmt.Sprintf("cluster-up-%s", c.Verify)}
+	var failures []string
+	for _, check := range checks {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		logPath := filepath.Join(verifyDir, sanitizeCheckName(check.name)+".log")
+		logFile, err := os.Create(logPath)
+		if err != nil {
+			return errors.NewError("cannot create verify log %q", logPath).WithCause(err)
+		}
+		start := time.Now()
+		checkErr := check.fn(logFile)
+		logFile.Close()
+		testCase := junitTestCase{Name: check.name, Time: time.Since(start).Seconds()}
+		if checkErr != nil {
+			fmt.Fprintf(out, "FAIL: %s: %v (see %s)\n", check.name, checkErr, logPath)
+			testCase.Failure = &junitFailure{Message: checkErr.Error()}
+			failures = append(failures, check.name)
+		} else {
+			fmt.Fprintf(out, "PASS: %s\n", check.name)
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Tests = len(suite.TestCases)
+	suite.Failures = len(failures)
+
+	if err := writeJUnitReport(filepath.Join(verifyDir, "junit.xml"), suite); err != nil {
+		fmt.Fprintf(out, "warning: cannot write JUnit report: %v\n", err)
+	}
+
+	if len(failures) > 0 {
+		return errors.NewError("cluster verification failed: %v", failures).WithDetails(fmt.Sprintf("see %s for logs and junit.xml", verifyDir))
+	}
+	return nil
+}
+
+func (c *ClientStartConfig) verifyCreateProject(out io.Writer) error {
+	f, err := openshift.LoggedInUserFactory()
+	if err != nil {
+		return errors.NewError("cannot get logged in user client").WithCause(err)
+	}
+	return openshift.CreateProject(f, verifyProjectName, verifyProjectName, "Created by oc cluster up --verify", "oc", out)
+}
+
+func (c *ClientStartConfig) verifyBuildAndDeploy(out io.Writer) error {
+	return localcmd.New("oc").Args(
+		"new-app", verifySampleImage, "--name=cluster-verify", "-n", verifyProjectName,
+	).Run()
+}
+
+func (c *ClientStartConfig) verifyRoute(out io.Writer) error {
+	return localcmd.New("oc").Args(
+		"expose", "service/cluster-verify", "-n", verifyProjectName,
+	).Run()
+}
+
+func (c *ClientStartConfig) verifyRouteReachable(out io.Writer) error {
+	host := fmt.Sprintf("cluster-verify-%s.%s", verifyProjectName, c.RoutingSuffix)
+	return localcmd.New("curl").Args("-sk", "--max-time", "30", "http://"+host).Run()
+}
+
+func (c *ClientStartConfig) verifyRegistry(out io.Writer) error {
+	registryHost := fmt.Sprintf("%s:5000", openshift.RegistryServiceIP)
+	tag := registryHost + "/" + verifyProjectName + "/cluster-verify-roundtrip:latest"
+	if err := localcmd.New("docker").Args("pull", verifySampleImage).Run(); err != nil {
+		return err
+	}
+	if err := localcmd.New("docker").Args("tag", verifySampleImage, tag).Run(); err != nil {
+		return err
+	}
+	if err := localcmd.New("docker").Args("push", tag).Run(); err != nil {
+		return err
+	}
+	if err := localcmd.New("docker").Args("rmi", tag).Run(); err != nil {
+		return err
+	}
+	return localcmd.New("docker").Args("pull", tag).Run()
+}
+
+func (c *ClientStartConfig) verifyServiceCatalog(out io.Writer) error {
+	return localcmd.New("oc").Args(
+		"get", "apiservices", "v1beta1.servicecatalog.k8s.io",
+	).Run()
+}
+
+func (c *ClientStartConfig) verifyTemplateServiceBroker(out io.Writer) error {
+	return localcmd.New("oc").Args(
+		"get", "clusterservicebroker", "template-service-broker",
+	).Run()
+}
+
+func sanitizeCheckName(name string) string {
+	out := make([]rune, 0, len(name))
+	for _, r := range name {
+		if r == ' ' || r == '/' {
+			out = append(out, '-')
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// junitTestSuite and its fields are a minimal subset of the JUnit XML schema
+// sufficient for CI systems to show pass/fail per check; see
+// https://llg.cubic.org/docs/junit/ for the full schema this is a subset of.
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Time    float64       `xml:"time,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+func writeJUnitReport(path string, suite junitTestSuite) error {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), 0644)
+}
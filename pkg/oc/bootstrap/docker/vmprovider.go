@@ -0,0 +1,77 @@
+package docker
+
+import (
+	"fmt"
+
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/dockerhelper"
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/dockermachine"
+)
+
+const (
+	// VMDriverDockerMachine backs --docker-machine/--create-machine with
+	// docker-machine, exactly as 'oc cluster up' always has.
+	VMDriverDockerMachine = "docker-machine"
+	// VMDriverQEMU backs --docker-machine with a self-managed QEMU VM
+	// instead, for platforms without docker-machine installed; the VM is
+	// created on its first Start. --create-machine still runs
+	// docker-machine create whatever --vm-driver is. See qemuVMProvider.
+	VMDriverQEMU = "qemu"
+)
+
+// VMProvider manages the lifecycle of a single named VM that runs the
+// Docker daemon 'oc cluster up' drives, abstracting over how that VM is
+// created and reached so determineIP and getDockerClient don't need to know
+// whether --docker-machine names a docker-machine-managed VM or one of the
+// VMDriverQEMU VMs this package manages itself.
+type VMProvider interface {
+	// Start creates the named VM if it doesn't already exist, and ensures it is running.
+	Start(name string) error
+	// IsRunning reports whether the named VM is currently running.
+	IsRunning(name string) bool
+	// IP returns the address 'oc cluster up' should reach the named VM's
+	// Docker daemon and OpenShift server on.
+	IP(name string) (string, error)
+	// Client returns a Docker client wired up to talk to the named VM's Docker daemon.
+	Client(name string) (dockerhelper.Interface, error)
+	// Stop shuts the named VM down without destroying it.
+	Stop(name string) error
+}
+
+// vmProvider resolves the VMProvider --vm-driver selects. The empty string
+// keeps the original docker-machine-backed behavior so existing
+// --docker-machine/--create-machine usage is unaffected.
+func (c *CommonStartConfig) vmProvider() (VMProvider, error) {
+	switch c.VMDriver {
+	case "", VMDriverDockerMachine:
+		return dockerMachineProvider{}, nil
+	case VMDriverQEMU:
+		return newQEMUProvider(c), nil
+	default:
+		return nil, fmt.Errorf("unknown --vm-driver %q", c.VMDriver)
+	}
+}
+
+// dockerMachineProvider is a thin VMProvider wrapper around the
+// docker-machine package calls determineIP and getDockerMachineClient made
+// directly before VMProvider existed.
+type dockerMachineProvider struct{}
+
+func (dockerMachineProvider) Start(name string) error {
+	return dockermachine.Start(name)
+}
+
+func (dockerMachineProvider) IsRunning(name string) bool {
+	return dockermachine.IsRunning(name)
+}
+
+func (dockerMachineProvider) IP(name string) (string, error) {
+	return dockermachine.IP(name)
+}
+
+func (dockerMachineProvider) Client(name string) (dockerhelper.Interface, error) {
+	return dockermachine.Client(name)
+}
+
+func (dockerMachineProvider) Stop(name string) error {
+	return dockermachine.Stop(name)
+}
@@ -0,0 +1,313 @@
+package docker
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kclientcmd "k8s.io/client-go/tools/clientcmd"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+	kclientset "k8s.io/kubernetes/pkg/client/clientset_generated/internalclientset"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/templates"
+
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/dockerhelper"
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/errors"
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/host"
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/localcmd"
+	"github.com/openshift/origin/pkg/oc/bootstrap/docker/openshift"
+	"github.com/openshift/origin/pkg/oc/cli/util/clientcmd"
+	osclientcmd "github.com/openshift/origin/pkg/oc/cli/util/clientcmd"
+)
+
+const (
+	// CmdVolumeRecommendedName is the recommended command name for "cluster volume"
+	CmdVolumeRecommendedName = "volume"
+
+	volumeNamePrefix  = "pv-dynamic-"
+	volumeAnnotation  = "oc.openshift.io/cluster-volume"
+	defaultVolumeSize = "1Gi"
+)
+
+var volumeLong = templates.LongDesc(`
+	Add, list, and remove hostPath PersistentVolumes on a running cluster started with 'oc cluster up'.
+
+	Unlike the fixed pool of PersistentVolumes created at 'cluster up' time, this command talks to the
+	already-running master to create additional hostPath PVs on demand.`)
+
+// NewCmdVolume creates the "cluster volume" command with its add/list/remove subcommands.
+func NewCmdVolume(name, fullName string, f *osclientcmd.Factory, out, errout io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   name,
+		Short: "Manage persistent volumes on a running cluster",
+		Long:  volumeLong,
+	}
+	cmd.AddCommand(newCmdVolumeAdd(f, out, errout))
+	cmd.AddCommand(newCmdVolumeList(f, out, errout))
+	cmd.AddCommand(newCmdVolumeRemove(f, out, errout))
+	return cmd
+}
+
+// volumeConfig holds the state shared by the add/list/remove subcommands:
+// enough of a Docker and OpenShift client to talk to an already-running
+// 'cluster up' cluster.
+type volumeConfig struct {
+	DockerMachine            string
+	HostConfigDir            string
+	HostPersistentVolumesDir string
+	ServerIP                 string
+
+	dockerClient dockerhelper.Interface
+	dockerHelper *dockerhelper.Helper
+	hostHelper   *host.HostHelper
+	factory      *clientcmd.Factory
+}
+
+func (c *volumeConfig) Bind(flags *pflag.FlagSet) {
+	flags.StringVar(&c.DockerMachine, "docker-machine", "", "Specify the Docker machine to use")
+	flags.StringVar(&c.HostConfigDir, "host-config-dir", host.DefaultConfigDir, "Directory on Docker host for OpenShift configuration")
+	flags.StringVar(&c.HostPersistentVolumesDir, "host-pv-dir", host.DefaultPersistentVolumesDir, "Directory on host under which new PersistentVolume directories are created")
+}
+
+// Complete connects to the Docker daemon and the running OpenShift master so
+// the subcommands can create host directories and PersistentVolume objects.
+func (c *volumeConfig) Complete(f *osclientcmd.Factory, out io.Writer) error {
+	client, err := getDockerClient(out, nil, c.DockerMachine, false)
+	if err != nil {
+		return err
+	}
+	c.dockerClient = client
+	c.dockerHelper = dockerhelper.NewHelper(c.dockerClient)
+
+	container, running, err := c.dockerHelper.GetContainerState(openshift.OpenShiftContainer)
+	if err != nil {
+		return errors.NewError("unexpected error while checking OpenShift container state").WithCause(err)
+	}
+	if container == nil || !running {
+		return errors.NewError("OpenShift is not running").WithSolution("Start a cluster with 'oc cluster up' before managing volumes.")
+	}
+
+	ip := c.dockerHelper.HostIP()
+	if len(ip) == 0 {
+		ip = "127.0.0.1"
+	}
+	c.ServerIP = ip
+
+	openshiftHelper := openshift.NewHelper(c.dockerHelper, c.HostHelper(), "", openshift.OpenShiftContainer, "", "")
+	serverIP, err := openshiftHelper.ServerIP()
+	if err == nil && len(serverIP) > 0 {
+		c.ServerIP = serverIP
+	}
+
+	cfg, err := kclientcmd.LoadFromFile(filepath.Join(c.HostConfigDir, "master", "admin.kubeconfig"))
+	if err != nil {
+		return errors.NewError("cannot load admin credentials").WithCause(err).WithSolution("Start a cluster with 'oc cluster up' before managing volumes.")
+	}
+	overrides := &kclientcmd.ConfigOverrides{}
+	overrides.ClusterInfo.Server = fmt.Sprintf("https://%s:8443", c.ServerIP)
+	c.factory = clientcmd.NewFactory(kclientcmd.NewDefaultClientConfig(*cfg, overrides))
+	return nil
+}
+
+func (c *volumeConfig) HostHelper() *host.HostHelper {
+	if c.hostHelper == nil {
+		c.hostHelper = host.NewHostHelper(c.dockerHelper, "", "", c.HostConfigDir, "", c.HostPersistentVolumesDir)
+	}
+	return c.hostHelper
+}
+
+func (c *volumeConfig) Clients() (kclientset.Interface, error) {
+	return c.factory.ClientSet()
+}
+
+// ensureHostPath creates the backing directory for a new PersistentVolume
+// inside the running OpenShift container's host mount.
+func (c *volumeConfig) ensureHostPath(path string) error {
+	return localcmd.New("docker").Args("exec", openshift.OpenShiftContainer, "mkdir", "-p", path).Run()
+}
+
+func newCmdVolumeAdd(f *osclientcmd.Factory, out, errout io.Writer) *cobra.Command {
+	config := &volumeConfig{}
+	opts := struct {
+		Count         int
+		Size          string
+		AccessModes   []string
+		ReclaimPolicy string
+	}{Count: 1, Size: defaultVolumeSize, AccessModes: []string{"ReadWriteOnce"}, ReclaimPolicy: "Recycle"}
+
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add one or more hostPath PersistentVolumes to the running cluster",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := config.Complete(f, out); err != nil {
+				fmt.Fprintf(errout, "%s\n", err)
+				return
+			}
+			if err := addVolumes(config, opts.Count, opts.Size, opts.AccessModes, opts.ReclaimPolicy, out); err != nil {
+				fmt.Fprintf(errout, "%s\n", err)
+			}
+		},
+	}
+	config.Bind(cmd.Flags())
+	cmd.Flags().IntVar(&opts.Count, "count", opts.Count, "Number of PersistentVolumes to create")
+	cmd.Flags().StringVar(&opts.Size, "size", opts.Size, "Capacity of each created PersistentVolume, e.g. 1Gi")
+	cmd.Flags().StringSliceVar(&opts.AccessModes, "access-modes", opts.AccessModes, "Access modes for each created PersistentVolume")
+	cmd.Flags().StringVar(&opts.ReclaimPolicy, "reclaim-policy", opts.ReclaimPolicy, "Reclaim policy for each created PersistentVolume: Retain, Recycle, or Delete")
+	return cmd
+}
+
+func addVolumes(config *volumeConfig, count int, size string, accessModeNames []string, reclaimPolicy string, out io.Writer) error {
+	if count < 1 {
+		return fmt.Errorf("--count must be at least 1")
+	}
+	capacity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return fmt.Errorf("invalid --size %q: %v", size, err)
+	}
+	accessModes := make([]kapi.PersistentVolumeAccessMode, 0, len(accessModeNames))
+	for _, name := range accessModeNames {
+		accessModes = append(accessModes, kapi.PersistentVolumeAccessMode(name))
+	}
+
+	kClient, err := config.Clients()
+	if err != nil {
+		return err
+	}
+	existing, err := kClient.Core().PersistentVolumes().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	nextIndex := nextVolumeIndex(existing.Items)
+
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("%s%04d", volumeNamePrefix, nextIndex+i)
+		hostPath := fmt.Sprintf("%s/%s", config.HostPersistentVolumesDir, name)
+		if err := config.ensureHostPath(hostPath); err != nil {
+			return fmt.Errorf("cannot create host directory %q: %v", hostPath, err)
+		}
+		pv := &kapi.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{volumeAnnotation: "true"},
+			},
+			Spec: kapi.PersistentVolumeSpec{
+				Capacity:                      kapi.ResourceList{kapi.ResourceStorage: capacity},
+				AccessModes:                   accessModes,
+				PersistentVolumeReclaimPolicy: kapi.PersistentVolumeReclaimPolicy(reclaimPolicy),
+				PersistentVolumeSource: kapi.PersistentVolumeSource{
+					HostPath: &kapi.HostPathVolumeSource{Path: hostPath},
+				},
+			},
+		}
+		if _, err := kClient.Core().PersistentVolumes().Create(pv); err != nil {
+			return fmt.Errorf("cannot create PersistentVolume %q: %v", name, err)
+		}
+		fmt.Fprintf(out, "Created PersistentVolume %q (%s, %s)\n", name, size, hostPath)
+	}
+	return nil
+}
+
+// nextVolumeIndex returns the next free numeric suffix among existing
+// PersistentVolumes named with volumeNamePrefix, so repeated 'volume add'
+// invocations don't collide.
+func nextVolumeIndex(pvs []kapi.PersistentVolume) int {
+	max := -1
+	for _, pv := range pvs {
+		if !strings.HasPrefix(pv.Name, volumeNamePrefix) {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(pv.Name, volumeNamePrefix)); err == nil && n > max {
+			max = n
+		}
+	}
+	return max + 1
+}
+
+func newCmdVolumeList(f *osclientcmd.Factory, out, errout io.Writer) *cobra.Command {
+	config := &volumeConfig{}
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List hostPath PersistentVolumes created by 'oc cluster volume add'",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := config.Complete(f, out); err != nil {
+				fmt.Fprintf(errout, "%s\n", err)
+				return
+			}
+			if err := listVolumes(config, out); err != nil {
+				fmt.Fprintf(errout, "%s\n", err)
+			}
+		},
+	}
+	config.Bind(cmd.Flags())
+	return cmd
+}
+
+func listVolumes(config *volumeConfig, out io.Writer) error {
+	kClient, err := config.Clients()
+	if err != nil {
+		return err
+	}
+	pvs, err := kClient.Core().PersistentVolumes().List(metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tCAPACITY\tACCESS MODES\tRECLAIM POLICY\tHOST PATH\tSTATUS")
+	for _, pv := range pvs.Items {
+		if _, ok := pv.Annotations[volumeAnnotation]; !ok {
+			continue
+		}
+		hostPath := ""
+		if pv.Spec.HostPath != nil {
+			hostPath = pv.Spec.HostPath.Path
+		}
+		fmt.Fprintf(w, "%s\t%s\t%v\t%s\t%s\t%s\n",
+			pv.Name, pv.Spec.Capacity[kapi.ResourceStorage].String(), pv.Spec.AccessModes,
+			pv.Spec.PersistentVolumeReclaimPolicy, hostPath, pv.Status.Phase)
+	}
+	return w.Flush()
+}
+
+func newCmdVolumeRemove(f *osclientcmd.Factory, out, errout io.Writer) *cobra.Command {
+	config := &volumeConfig{}
+	cmd := &cobra.Command{
+		Use:   "remove NAME [NAME...]",
+		Short: "Remove hostPath PersistentVolumes created by 'oc cluster volume add'",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(args) == 0 {
+				fmt.Fprintf(errout, "at least one PersistentVolume name is required\n")
+				return
+			}
+			if err := config.Complete(f, out); err != nil {
+				fmt.Fprintf(errout, "%s\n", err)
+				return
+			}
+			if err := removeVolumes(config, args, out); err != nil {
+				fmt.Fprintf(errout, "%s\n", err)
+			}
+		},
+	}
+	config.Bind(cmd.Flags())
+	return cmd
+}
+
+func removeVolumes(config *volumeConfig, names []string, out io.Writer) error {
+	kClient, err := config.Clients()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := kClient.Core().PersistentVolumes().Delete(name, nil); err != nil {
+			return fmt.Errorf("cannot remove PersistentVolume %q: %v", name, err)
+		}
+		fmt.Fprintf(out, "Removed PersistentVolume %q\n", name)
+	}
+	return nil
+}
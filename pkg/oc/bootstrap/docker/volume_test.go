@@ -0,0 +1,54 @@
+package docker
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestNextVolumeIndex(t *testing.T) {
+	pv := func(name string) kapi.PersistentVolume {
+		return kapi.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	tests := []struct {
+		name string
+		pvs  []kapi.PersistentVolume
+		want int
+	}{
+		{
+			name: "no volumes",
+			pvs:  nil,
+			want: 0,
+		},
+		{
+			name: "no matching volumes",
+			pvs:  []kapi.PersistentVolume{pv("pvc-abcd"), pv("registry-storage")},
+			want: 0,
+		},
+		{
+			name: "contiguous volumes",
+			pvs:  []kapi.PersistentVolume{pv(volumeNamePrefix + "0000"), pv(volumeNamePrefix + "0001")},
+			want: 2,
+		},
+		{
+			name: "out of order volumes",
+			pvs:  []kapi.PersistentVolume{pv(volumeNamePrefix + "0003"), pv(volumeNamePrefix + "0001")},
+			want: 4,
+		},
+		{
+			name: "matching and non-matching volumes mixed",
+			pvs:  []kapi.PersistentVolume{pv("other-volume"), pv(volumeNamePrefix + "0002")},
+			want: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextVolumeIndex(tt.pvs); got != tt.want {
+				t.Errorf("nextVolumeIndex(%v) = %d, want %d", tt.pvs, got, tt.want)
+			}
+		})
+	}
+}